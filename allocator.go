@@ -0,0 +1,141 @@
+/* Asignadores de nodos para cargas con muchas inserciones y borrados.
+   Por defecto cada Insert crea un Node nuevo y el recolector de basura se
+   encarga de los nodos borrados. Con un asignador los nodos borrados se
+   reutilizan (PoolAllocator) o se toman de bloques grandes que se liberan
+   de una sola vez al llamar Clear (ArenaAllocator).
+
+   Un nodo devuelto por Find deja de ser válido después de borrarlo o de
+   llamar Clear, pues el asignador puede reutilizarlo para otro valor.
+*/
+
+package redBlackTree
+
+import (
+      "sync"
+)
+
+// Un asignador entrega nodos vacíos y recibe los que se borran del árbol.
+// Free recibe un nodo ya limpio (con todos sus campos en cero) y FreeAll el
+// subárbol completo que se elimina con Clear.
+type Allocator interface {
+      Alloc() *Node
+      Free(*Node)
+      FreeAll(root *Node)
+}
+
+// SetAllocator hace que el árbol tome sus nodos de pAlloc. Solamente puede
+// cambiarse el asignador con el árbol vacío.
+func (tree *RBTree) SetAllocator(pAlloc Allocator) {
+      if tree.root != nil {
+            panic("Solamente se puede cambiar el asignador de un árbol vacío")
+      }
+      tree.alloc = pAlloc
+}
+
+// newNode crea un nodo rojo con el valor y el padre dados.
+func (tree *RBTree) newNode(pValue interface{}, pParent *Node) *Node {
+      if tree.alloc == nil {
+            return &Node{value: pValue, parent: pParent}
+      }
+      node := tree.alloc.Alloc()
+      node.value = pValue
+      node.parent = pParent
+      return node
+}
+
+// freeNode devuelve un nodo ya limpio al asignador, si el árbol tiene uno.
+func (tree *RBTree) freeNode(node *Node) {
+      if tree.alloc != nil {
+            tree.alloc.Free(node)
+      }
+}
+
+// PoolAllocator reutiliza los nodos borrados mediante un sync.Pool, por lo
+// que puede compartirse entre árboles de distintas goroutines.
+type PoolAllocator struct {
+      pool sync.Pool
+}
+
+// Se define un nuevo asignador con un pool vacío.
+func NewPoolAllocator() *PoolAllocator {
+      return &PoolAllocator{pool: sync.Pool{New: func() interface{} {
+            return &Node{}
+      }}}
+}
+
+func (alloc *PoolAllocator) Alloc() *Node {
+      return alloc.pool.Get().(*Node)
+}
+
+func (alloc *PoolAllocator) Free(node *Node) {
+      alloc.pool.Put(node)
+}
+
+// FreeAll limpia los nodos del subárbol en postorder y los devuelve al pool.
+func (alloc *PoolAllocator) FreeAll(root *Node) {
+      if root != nil {
+            alloc.FreeAll(root.left)
+            alloc.FreeAll(root.right)
+            root.clear()
+            alloc.pool.Put(root)
+      }
+}
+
+// ArenaAllocator toma los nodos de bloques de chunkSize nodos, y reutiliza
+// los borrados mediante una lista libre. FreeAll cuesta O(1): no recorre el
+// árbol ni limpia los bloques, sino que vuelve a entregar nodos desde el
+// primero, y Alloc limpia cada nodo al reutilizarlo. Así los bloques sirven
+// en cada ciclo de llenar y vaciar el árbol sin pedir memoria nueva, pero los
+// valores borrados con Clear siguen referenciados hasta que se reutilice su
+// nodo o se descarte el asignador. No es seguro usarlo desde varias
+// goroutines ni compartirlo entre árboles, pues FreeAll libera todos los
+// nodos del asignador.
+type ArenaAllocator struct {
+      chunkSize int
+      chunks    [][]Node
+      // Bloque en uso y siguiente nodo sin usar de ese bloque.
+      current, next int
+      free          []*Node
+}
+
+// Se define un nuevo asignador por bloques. Si pChunkSize no es positivo se
+// usan bloques de 1024 nodos.
+func NewArenaAllocator(pChunkSize int) *ArenaAllocator {
+      if pChunkSize <= 0 {
+            pChunkSize = 1024
+      }
+      return &ArenaAllocator{chunkSize: pChunkSize}
+}
+
+func (alloc *ArenaAllocator) Alloc() *Node {
+      if n := len(alloc.free); n > 0 {
+            node := alloc.free[n-1]
+            alloc.free = alloc.free[:n-1]
+            return node
+      }
+      // Cuando se acaba el bloque actual se pasa al siguiente, y solamente si
+      // no hay más se pide uno nuevo.
+      if alloc.next == alloc.chunkSize {
+            alloc.current++
+            alloc.next = 0
+      }
+      if alloc.current == len(alloc.chunks) {
+            alloc.chunks = append(alloc.chunks, make([]Node, alloc.chunkSize))
+      }
+      // El nodo puede tener los datos de un ciclo anterior a FreeAll.
+      node := &alloc.chunks[alloc.current][alloc.next]
+      *node = Node{}
+      alloc.next++
+      return node
+}
+
+func (alloc *ArenaAllocator) Free(node *Node) {
+      alloc.free = append(alloc.free, node)
+}
+
+// FreeAll vuelve a entregar nodos desde el primer bloque y vacía la lista
+// libre, sin recorrer el árbol ni limpiar los nodos.
+func (alloc *ArenaAllocator) FreeAll(root *Node) {
+      alloc.current, alloc.next = 0, 0
+      alloc.free = alloc.free[:0]
+}
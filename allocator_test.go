@@ -0,0 +1,75 @@
+package redBlackTree
+
+import (
+      "testing"
+)
+
+// Llena y vacía el árbol varias veces, con borrados de por medio, para
+// revisar que los nodos reutilizados quedan bien.
+func TestAllocatorsFillClear(t *testing.T) {
+      for _, alloc := range benchAllocators {
+            t.Run(alloc.name, func(t *testing.T) {
+                  tree := newBenchTree(alloc.new())
+                  for round := 0; round < 3; round++ {
+                        for i := 0; i < 3000; i++ {
+                              tree.Insert(i)
+                        }
+                        for i := 0; i < 3000; i += 3 {
+                              tree.Delete(i)
+                        }
+                        if err := tree.Validate(); err != nil {
+                              t.Fatalf("ronda %d: %v", round, err)
+                        }
+                        if tree.Len() != 2000 {
+                              t.Fatalf("ronda %d: Len() = %d", round, tree.Len())
+                        }
+                        tree.Clear()
+                  }
+            })
+      }
+}
+
+func TestArenaAllocatorReusesChunks(t *testing.T) {
+      arena := NewArenaAllocator(16)
+      tree := newBenchTree(arena)
+      for i := 0; i < 100; i++ {
+            tree.Insert(i)
+      }
+      chunks := len(arena.chunks)
+      tree.Clear()
+
+      // Después de Clear se reutiliza el primer nodo, limpio aunque haya
+      // guardado un valor del ciclo anterior.
+      first := &arena.chunks[0][0]
+      if node := arena.Alloc(); node != first || *node != (Node{}) {
+            t.Fatalf("Alloc() = %p %v, se esperaba el primer nodo limpio", node, node.value)
+      }
+      arena.FreeAll(nil)
+
+      for i := 0; i < 100; i++ {
+            tree.Insert(i)
+      }
+      if len(arena.chunks) != chunks {
+            t.Fatalf("se pidieron bloques nuevos: %d, antes %d", len(arena.chunks), chunks)
+      }
+      if err := tree.Validate(); err != nil {
+            t.Fatal(err)
+      }
+}
+
+// Con un asignador que reutiliza nodos, insertar y borrar un valor no reserva
+// memoria si no hay bitácora de deshacer ni suscriptores.
+func TestChurnDoesNotAllocate(t *testing.T) {
+      tree := newBenchTree(NewPoolAllocator())
+      for i := 0; i < 1000; i += 2 {
+            tree.Insert(i)
+      }
+      var value interface{} = 501
+      allocs := testing.AllocsPerRun(1000, func() {
+            tree.Insert(value)
+            tree.Delete(value)
+      })
+      if allocs != 0 {
+            t.Fatalf("Insert y Delete reservan memoria %v veces", allocs)
+      }
+}
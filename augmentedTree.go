@@ -0,0 +1,201 @@
+/* Árboles aumentados con agregados definidos por el usuario.
+   Cada nodo guarda el agregado (suma, mínimo, conteo, ...) de todo su
+   subárbol, de modo que el agregado de un rango de llaves se obtiene
+   combinando O(log n) nodos. Un árbol tiene un solo monoide; para mantener
+   varios agregados a la vez (por ejemplo, tamaños para Rank y Select y
+   resúmenes de Merkle) se juntan con Monoids.
+*/
+
+package redBlackTree
+
+// Un monoide define cómo se agregan los valores de un subárbol. Identity es el
+// agregado de un subárbol vacío y Combine junta el agregado del subárbol
+// izquierdo, el valor del nodo y el agregado del subárbol derecho, en ese orden.
+// Combine debe ser asociativa para que los agregados de rangos sean correctos.
+type Monoid interface {
+      Identity() interface{}
+      Combine(left, self, right interface{}) interface{}
+}
+
+// Se define un nuevo árbol con un comparador y un monoide, cuyos nodos
+// mantienen el agregado de su subárbol al insertar, borrar y rotar.
+func NewAugmentedTree(pCmp Cmp, pMonoid Monoid) *RBTree {
+      tree := NewTree(pCmp)
+      tree.monoid = pMonoid
+      tree.update = func(node *Node) {
+            node.aug = pMonoid.Combine(tree.aggOf(node.left), node.value, tree.aggOf(node.right))
+      }
+      return tree
+}
+
+// aggOf devuelve el agregado del subárbol de node, o la identidad si es nulo.
+func (tree *RBTree) aggOf(node *Node) interface{} {
+      if node == nil {
+            return tree.monoid.Identity()
+      }
+      return node.aug
+}
+
+// Monoids junta varios monoides en uno. Su agregado es un []interface{} con
+// el agregado de cada monoide, en el mismo orden. Rank, Select y los resúmenes
+// de Merkle encuentran su monoide dentro de Monoids, pero no dentro de un
+// Monoids anidado.
+type Monoids []Monoid
+
+func (ms Monoids) Identity() interface{} {
+      aggs := make([]interface{}, len(ms))
+      for i, m := range ms {
+            aggs[i] = m.Identity()
+      }
+      return aggs
+}
+
+func (ms Monoids) Combine(left, self, right interface{}) interface{} {
+      lefts, rights := left.([]interface{}), right.([]interface{})
+      aggs := make([]interface{}, len(ms))
+      for i, m := range ms {
+            aggs[i] = m.Combine(lefts[i], self, rights[i])
+      }
+      return aggs
+}
+
+// findMonoid busca el monoide del árbol que cumple pMatch, ya sea el monoide
+// mismo o uno de los que junta Monoids. Devuelve también su posición en
+// Monoids, o -1 si es el monoide del árbol.
+func (tree *RBTree) findMonoid(pMatch func(Monoid) bool) (Monoid, int, bool) {
+      if tree.monoid == nil {
+            return nil, 0, false
+      }
+      if pMatch(tree.monoid) {
+            return tree.monoid, -1, true
+      }
+      if ms, ok := tree.monoid.(Monoids); ok {
+            for i, m := range ms {
+                  if pMatch(m) {
+                        return m, i, true
+                  }
+            }
+      }
+      return nil, 0, false
+}
+
+// aggPart devuelve el agregado del subárbol de node para el monoide en la
+// posición i que devolvió findMonoid.
+func (tree *RBTree) aggPart(node *Node, i int) interface{} {
+      agg := tree.aggOf(node)
+      if i < 0 {
+            return agg
+      }
+      return agg.([]interface{})[i]
+}
+
+// AggregateAll devuelve el agregado de todos los valores del árbol.
+func (tree *RBTree) AggregateAll() interface{} {
+      if tree.monoid == nil {
+            panic("El árbol no tiene monoide")
+      }
+      return tree.aggOf(tree.root)
+}
+
+// Aggregate devuelve el agregado de los valores en el intervalo cerrado
+// [pLow, pHigh]. Se baja hasta el primer nodo dentro del rango y desde allí se
+// siguen dos caminos, uno hacia cada límite, usando los agregados guardados de
+// los subárboles que quedan completamente dentro del rango.
+func (tree *RBTree) Aggregate(pLow, pHigh interface{}) interface{} {
+      if tree.monoid == nil {
+            panic("El árbol no tiene monoide")
+      }
+
+      node := tree.root
+      for node != nil {
+            switch {
+            case tree.cmp(node.value, pLow) < 0:
+                  node = node.right
+            case tree.cmp(node.value, pHigh) > 0:
+                  node = node.left
+            default:
+                  return tree.monoid.Combine(tree.aggFrom(node.left, pLow), node.value, tree.aggUpTo(node.right, pHigh))
+            }
+      }
+      return tree.monoid.Identity()
+}
+
+// aggFrom devuelve el agregado de los valores mayores o iguales a pLow en el
+// subárbol de node.
+func (tree *RBTree) aggFrom(node *Node, pLow interface{}) interface{} {
+      if node == nil {
+            return tree.monoid.Identity()
+      }
+      if tree.cmp(node.value, pLow) < 0 {
+            return tree.aggFrom(node.right, pLow)
+      }
+      return tree.monoid.Combine(tree.aggFrom(node.left, pLow), node.value, tree.aggOf(node.right))
+}
+
+// aggUpTo devuelve el agregado de los valores menores o iguales a pHigh en el
+// subárbol de node.
+func (tree *RBTree) aggUpTo(node *Node, pHigh interface{}) interface{} {
+      if node == nil {
+            return tree.monoid.Identity()
+      }
+      if tree.cmp(node.value, pHigh) > 0 {
+            return tree.aggUpTo(node.left, pHigh)
+      }
+      return tree.monoid.Combine(tree.aggOf(node.left), node.value, tree.aggUpTo(node.right, pHigh))
+}
+
+// Monoides para árboles de enteros. Los de mínimo y máximo usan nil como
+// identidad, de modo que el agregado de un rango vacío es nil.
+
+type SumMonoid struct{}
+
+func (SumMonoid) Identity() interface{} {
+      return 0
+}
+
+func (SumMonoid) Combine(left, self, right interface{}) interface{} {
+      return left.(int) + self.(int) + right.(int)
+}
+
+// CountMonoid cuenta los nodos, por lo que sirve para árboles de cualquier tipo.
+type CountMonoid struct{}
+
+func (CountMonoid) Identity() interface{} {
+      return 0
+}
+
+func (CountMonoid) Combine(left, self, right interface{}) interface{} {
+      return left.(int) + 1 + right.(int)
+}
+
+type MinMonoid struct{}
+
+func (MinMonoid) Identity() interface{} {
+      return nil
+}
+
+func (MinMonoid) Combine(left, self, right interface{}) interface{} {
+      min := self.(int)
+      for _, agg := range []interface{}{left, right} {
+            if agg != nil && agg.(int) < min {
+                  min = agg.(int)
+            }
+      }
+      return min
+}
+
+type MaxMonoid struct{}
+
+func (MaxMonoid) Identity() interface{} {
+      return nil
+}
+
+func (MaxMonoid) Combine(left, self, right interface{}) interface{} {
+      max := self.(int)
+      for _, agg := range []interface{}{left, right} {
+            if agg != nil && agg.(int) > max {
+                  max = agg.(int)
+            }
+      }
+      return max
+}
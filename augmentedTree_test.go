@@ -0,0 +1,142 @@
+package redBlackTree
+
+import (
+      "math/rand"
+      "reflect"
+      "testing"
+)
+
+// aggregateSlow calcula el agregado de los valores en [pLow, pHigh]
+// combinándolos uno por uno.
+func aggregateSlow(pMonoid Monoid, pValues []int, pLow, pHigh int) interface{} {
+      agg := pMonoid.Identity()
+      for _, v := range pValues {
+            if v >= pLow && v <= pHigh {
+                  agg = pMonoid.Combine(agg, v, pMonoid.Identity())
+            }
+      }
+      return agg
+}
+
+func TestAggregateMatchesScan(t *testing.T) {
+      monoids := []struct {
+            name   string
+            monoid Monoid
+      }{
+            {"Sum", SumMonoid{}},
+            {"Count", CountMonoid{}},
+            {"Min", MinMonoid{}},
+            {"Max", MaxMonoid{}},
+      }
+      for _, m := range monoids {
+            t.Run(m.name, func(t *testing.T) {
+                  r := rand.New(rand.NewSource(1))
+                  tree := NewAugmentedTree(IntCmp, m.monoid)
+                  for step := 0; step < 1000; step++ {
+                        v := r.Intn(200)
+                        if r.Intn(3) == 0 {
+                              tree.Delete(v)
+                        } else {
+                              tree.Insert(v)
+                        }
+                        values := tree.contents()
+                        ints := make([]int, len(values))
+                        for i, value := range values {
+                              ints[i] = value.(int)
+                        }
+
+                        low := r.Intn(220) - 10
+                        high := low + r.Intn(60)
+                        if got, want := tree.Aggregate(low, high), aggregateSlow(m.monoid, ints, low, high); got != want {
+                              t.Fatalf("paso %d: Aggregate(%d, %d) = %v, se esperaba %v", step, low, high, got, want)
+                        }
+                        if got, want := tree.AggregateAll(), aggregateSlow(m.monoid, ints, -1, 1000); got != want {
+                              t.Fatalf("paso %d: AggregateAll() = %v, se esperaba %v", step, got, want)
+                        }
+                  }
+            })
+      }
+}
+
+func TestAggregateEmpty(t *testing.T) {
+      tree := NewAugmentedTree(IntCmp, SumMonoid{})
+      if tree.AggregateAll() != 0 || tree.Aggregate(1, 10) != 0 {
+            t.Fatal("el agregado de un árbol vacío no es la identidad")
+      }
+      tree = NewAugmentedTree(IntCmp, MinMonoid{})
+      tree.Insert(5)
+      if tree.Aggregate(6, 10) != nil {
+            t.Fatal("el mínimo de un rango vacío no es nil")
+      }
+      if tree.Aggregate(10, 1) != nil {
+            t.Fatal("el mínimo de un rango invertido no es nil")
+      }
+}
+
+func TestAggregateWithoutMonoidPanics(t *testing.T) {
+      defer func() {
+            if recover() == nil {
+                  t.Fatal("Aggregate no entró en pánico en un árbol sin monoide")
+            }
+      }()
+      NewTree(IntCmp).Aggregate(1, 2)
+}
+
+// Un árbol con Monoids mantiene todos los agregados: sirve para Rank, Select
+// y resúmenes de Merkle a la vez, con los mismos resultados que árboles
+// separados.
+func TestMonoidsCombine(t *testing.T) {
+      tree := NewAugmentedTree(IntCmp, Monoids{SumMonoid{}, CountMonoid{}, NewHashMonoid(intHasher)})
+      counts := NewOrderStatisticTree(IntCmp)
+      hashes := NewMerkleTree(IntCmp, intHasher)
+
+      r := rand.New(rand.NewSource(2))
+      for step := 0; step < 500; step++ {
+            v := r.Intn(100)
+            for _, each := range []*RBTree{tree, counts, hashes} {
+                  if step%4 == 3 {
+                        each.Delete(v)
+                  } else {
+                        each.Insert(v)
+                  }
+            }
+            if err := tree.Validate(); err != nil {
+                  t.Fatalf("paso %d: %v", step, err)
+            }
+            if tree.Rank(v) != counts.Rank(v) {
+                  t.Fatalf("paso %d: Rank(%d) = %d, se esperaba %d", step, v, tree.Rank(v), counts.Rank(v))
+            }
+            if i := r.Intn(tree.Len() + 1); !reflect.DeepEqual(valueOf(tree.Select(i)), valueOf(counts.Select(i))) {
+                  t.Fatalf("paso %d: Select(%d) no coincide", step, i)
+            }
+            if tree.RootHash() != hashes.RootHash() {
+                  t.Fatalf("paso %d: RootHash no coincide", step)
+            }
+            sum := tree.AggregateAll().([]interface{})
+            if sum[1] != tree.Len() {
+                  t.Fatalf("paso %d: AggregateAll() = %v con %d valores", step, sum, tree.Len())
+            }
+      }
+      if len(DiffRanges(tree, hashes)) != 0 {
+            t.Fatal("DiffRanges encontró diferencias entre árboles iguales")
+      }
+}
+
+// Rank entra en pánico si el árbol no mantiene tamaños, aunque tenga otros
+// monoides.
+func TestRankWithoutCountPanics(t *testing.T) {
+      defer func() {
+            if recover() == nil {
+                  t.Fatal("Rank no entró en pánico sin CountMonoid")
+            }
+      }()
+      NewAugmentedTree(IntCmp, Monoids{SumMonoid{}}).Rank(1)
+}
+
+// intHasher resume un entero con la función de mezcla de splitmix64.
+func intHasher(pValue interface{}) uint64 {
+      x := uint64(pValue.(int)) + 0x9e3779b97f4a7c15
+      x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+      x = (x ^ x>>27) * 0x94d049bb133111eb
+      return x ^ x>>31
+}
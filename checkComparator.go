@@ -0,0 +1,84 @@
+/* Revisión de comparadores definidos por el usuario.
+   El árbol supone que el comparador define un orden total; si no lo hace,
+   las búsquedas pueden no encontrar valores que sí están en el árbol.
+*/
+
+package redBlackTree
+
+import (
+      "fmt"
+)
+
+// ComparatorError indica qué propiedad no cumple un comparador y con qué
+// valores de prueba falla.
+type ComparatorError struct {
+      Property string
+      Values   []interface{}
+      Detail   string
+}
+
+func (e *ComparatorError) Error() string {
+      return fmt.Sprintf("el comparador no es %s con %v: %s", e.Property, e.Values, e.Detail)
+}
+
+// signOf convierte el resultado de una comparación en -1, 0 o 1.
+func signOf(compare int) int {
+      switch {
+      case compare > 0:
+            return 1
+      case compare < 0:
+            return -1
+      default:
+            return 0
+      }
+}
+
+// CheckComparator prueba pCmp con todos los pares y tríos de pSamples y
+// devuelve un *ComparatorError con la primera propiedad que no cumpla:
+//   - reflexivo: cmp(a, a) == 0
+//   - antisimétrico: cmp(a, b) y cmp(b, a) tienen signos opuestos
+//   - transitivo: si a <= b y b <= c, entonces a <= c
+// También se devuelve un error si el comparador entra en pánico. Como se
+// revisan todos los tríos, el costo es O(n³) en la cantidad de muestras.
+func CheckComparator(pCmp Cmp, pSamples []interface{}) (err error) {
+      var current []interface{}
+      defer func() {
+            if r := recover(); r != nil {
+                  err = &ComparatorError{Property: "total", Values: current, Detail: fmt.Sprint("entró en pánico: ", r)}
+            }
+      }()
+      cmp := func(o1, o2 interface{}) int {
+            current = []interface{}{o1, o2}
+            return signOf(pCmp(o1, o2))
+      }
+
+      for _, a := range pSamples {
+            if compare := cmp(a, a); compare != 0 {
+                  return &ComparatorError{"reflexivo", []interface{}{a}, fmt.Sprintf("cmp(a, a) = %d", compare)}
+            }
+      }
+
+      for _, a := range pSamples {
+            for _, b := range pSamples {
+                  if ab, ba := cmp(a, b), cmp(b, a); ab != -ba {
+                        return &ComparatorError{"antisimétrico", []interface{}{a, b},
+                              fmt.Sprintf("cmp(a, b) = %d y cmp(b, a) = %d", ab, ba)}
+                  }
+            }
+      }
+
+      for _, a := range pSamples {
+            for _, b := range pSamples {
+                  if cmp(a, b) > 0 {
+                        continue
+                  }
+                  for _, c := range pSamples {
+                        if cmp(b, c) <= 0 && cmp(a, c) > 0 {
+                              return &ComparatorError{"transitivo", []interface{}{a, b, c},
+                                    "a <= b y b <= c, pero a > c"}
+                        }
+                  }
+            }
+      }
+      return nil
+}
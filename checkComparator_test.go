@@ -0,0 +1,60 @@
+package redBlackTree
+
+import (
+      "errors"
+      "testing"
+)
+
+func TestCheckComparator(t *testing.T) {
+      samples := []interface{}{0, 1, 2, 3}
+      tests := []struct {
+            name     string
+            cmp      Cmp
+            property string
+      }{
+            {"legal", IntCmp, ""},
+            // Con magnitudes distintas de 1 el comparador sigue siendo válido.
+            {"legal con magnitudes", func(o1, o2 interface{}) int { return (o1.(int) - o2.(int)) * 7 }, ""},
+            {"no reflexivo", func(o1, o2 interface{}) int { return -1 }, "reflexivo"},
+            {"no antisimétrico", func(o1, o2 interface{}) int {
+                  if o1 == o2 {
+                        return 0
+                  }
+                  return 1
+            }, "antisimétrico"},
+            // Piedra, papel o tijera: cada valor es menor que el siguiente
+            // módulo 3, así que 0 < 1 < 2 < 0.
+            {"no transitivo", func(o1, o2 interface{}) int {
+                  a, b := o1.(int)%3, o2.(int)%3
+                  switch {
+                  case a == b:
+                        return 0
+                  case (b-a+3)%3 == 1:
+                        return -1
+                  default:
+                        return 1
+                  }
+            }, "transitivo"},
+            {"pánico", func(o1, o2 interface{}) int { return StringCmp(o1, o2) }, "total"},
+      }
+      for _, test := range tests {
+            err := CheckComparator(test.cmp, samples)
+            if test.property == "" {
+                  if err != nil {
+                        t.Errorf("%s: %v", test.name, err)
+                  }
+                  continue
+            }
+            var cmpErr *ComparatorError
+            if !errors.As(err, &cmpErr) {
+                  t.Errorf("%s: error %v, se esperaba un *ComparatorError", test.name, err)
+                  continue
+            }
+            if cmpErr.Property != test.property {
+                  t.Errorf("%s: propiedad %q, se esperaba %q (%v)", test.name, cmpErr.Property, test.property, err)
+            }
+            if len(cmpErr.Values) == 0 {
+                  t.Errorf("%s: el error no indica los valores", test.name)
+            }
+      }
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+      "bufio"
+      "bytes"
+      "encoding/json"
+      "fmt"
+      "io"
+      "strings"
+
+      redBlackTree "github.com/luahir/Tarea-1-LP"
+)
+
+// Resultado de ejecutar una línea del script.
+type opResult struct {
+      Line    int    `json:"line"`
+      Command string `json:"command"`
+      Output  string `json:"output,omitempty"`
+      Error   string `json:"error,omitempty"`
+}
+
+// Una operación en formato JSONL, por ejemplo {"op": "insert", "values": [5, 3]}.
+// Value y Values son equivalentes; Args se usa para los comandos que reciben
+// nombres de archivo.
+type jsonOp struct {
+      Op     string            `json:"op"`
+      Value  json.RawMessage   `json:"value"`
+      Values []json.RawMessage `json:"values"`
+      Args   []string          `json:"args"`
+}
+
+// Nodo del árbol tal como se despliega en el formato JSON.
+type jsonNode struct {
+      Value interface{} `json:"value"`
+      Color string      `json:"color"`
+      Left  *jsonNode   `json:"left,omitempty"`
+      Right *jsonNode   `json:"right,omitempty"`
+}
+
+// runBatch ejecuta el script de in, una operación por línea, y despliega en
+// out los resultados y el estado final del árbol en el formato indicado (text,
+// json o dot). Las líneas que empiezan con "{" se leen como JSON, las que
+// empiezan con "#" son comentarios. Devuelve true si alguna operación falló.
+func runBatch(s *session, in io.Reader, out io.Writer, format string) (bool, error) {
+      if format != "text" && format != "json" && format != "dot" {
+            return false, fmt.Errorf("formato desconocido %q (se espera text, json o dot)", format)
+      }
+
+      results := []opResult{}
+      failed := false
+      lineNum := 0
+
+      scanner := bufio.NewScanner(in)
+      for scanner.Scan() {
+            lineNum++
+            line := strings.TrimSpace(scanner.Text())
+            if line == "" || strings.HasPrefix(line, "#") {
+                  continue
+            }
+            if line == "exit" || line == "quit" {
+                  break
+            }
+
+            var buf bytes.Buffer
+            var err error
+            if strings.HasPrefix(line, "{") {
+                  var cmd string
+                  var args []string
+                  cmd, args, err = parseJSONOp(line)
+                  if err == nil {
+                        err = s.run(cmd, args, &buf)
+                  }
+            } else {
+                  err = s.exec(line, &buf)
+            }
+            s.history = append(s.history, line)
+
+            result := opResult{Line: lineNum, Command: line, Output: buf.String()}
+            if err != nil {
+                  result.Error = err.Error()
+                  failed = true
+            }
+            results = append(results, result)
+      }
+      if err := scanner.Err(); err != nil {
+            return failed, err
+      }
+
+      switch format {
+      case "text":
+            writeText(out, results, s.tree)
+      case "json":
+            if err := writeJSON(out, results, s.tree); err != nil {
+                  return failed, err
+            }
+      case "dot":
+            // Los resultados se escriben como comentarios para que el archivo
+            // siga siendo un DOT válido.
+            for _, r := range results {
+                  for _, l := range resultLines(r) {
+                        fmt.Fprintln(out, "//", l)
+                  }
+            }
+            fmt.Fprint(out, s.tree.Dot())
+      }
+      return failed, nil
+}
+
+// parseJSONOp convierte una línea JSONL en un comando y sus argumentos.
+func parseJSONOp(line string) (string, []string, error) {
+      var op jsonOp
+      decoder := json.NewDecoder(strings.NewReader(line))
+      decoder.DisallowUnknownFields()
+      if err := decoder.Decode(&op); err != nil {
+            return "", nil, fmt.Errorf("JSON inválido: %v", err)
+      }
+      if op.Op == "" {
+            return "", nil, fmt.Errorf("falta el campo \"op\"")
+      }
+
+      raws := op.Values
+      if op.Value != nil {
+            raws = append([]json.RawMessage{op.Value}, raws...)
+      }
+      args := append([]string{}, op.Args...)
+      for _, raw := range raws {
+            // Las hileras se usan sin comillas y los números tal como vienen.
+            var str string
+            if err := json.Unmarshal(raw, &str); err == nil {
+                  args = append(args, str)
+                  continue
+            }
+            var num json.Number
+            if err := json.Unmarshal(raw, &num); err != nil {
+                  return "", nil, fmt.Errorf("valor inválido %s", raw)
+            }
+            args = append(args, num.String())
+      }
+      return op.Op, args, nil
+}
+
+// resultLines devuelve las líneas con que se despliega un resultado en texto.
+func resultLines(r opResult) []string {
+      lines := []string{fmt.Sprintf("%d: %s", r.Line, r.Command)}
+      for _, l := range strings.Split(strings.TrimRight(r.Output, "\n"), "\n") {
+            if l != "" {
+                  lines = append(lines, "  "+l)
+            }
+      }
+      if r.Error != "" {
+            lines = append(lines, "  error: "+r.Error)
+      }
+      return lines
+}
+
+func writeText(out io.Writer, results []opResult, tree *redBlackTree.RBTree) {
+      for _, r := range results {
+            for _, l := range resultLines(r) {
+                  fmt.Fprintln(out, l)
+            }
+      }
+      fmt.Fprintln(out, "árbol final:", tree)
+      tree.PrettyFprint(out)
+}
+
+func writeJSON(out io.Writer, results []opResult, tree *redBlackTree.RBTree) error {
+      values := []interface{}{}
+      iter := &redBlackTree.InorderIterator{}
+      for node := range iter.Iterate(tree.Root()) {
+            values = append(values, node.Value())
+      }
+
+      doc := struct {
+            Results []opResult    `json:"results"`
+            Count   int           `json:"count"`
+            Values  []interface{} `json:"values"`
+            Root    *jsonNode     `json:"root"`
+      }{results, tree.Len(), values, toJSONNode(tree.Root())}
+
+      encoder := json.NewEncoder(out)
+      encoder.SetIndent("", "  ")
+      return encoder.Encode(doc)
+}
+
+func toJSONNode(node *redBlackTree.Node) *jsonNode {
+      if node == nil {
+            return nil
+      }
+      return &jsonNode{
+            Value: node.Value(),
+            Color: node.Color().String(),
+            Left:  toJSONNode(node.Left()),
+            Right: toJSONNode(node.Right()),
+      }
+}
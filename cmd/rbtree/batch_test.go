@@ -0,0 +1,81 @@
+package main
+
+import (
+      "bytes"
+      "flag"
+      "os"
+      "path/filepath"
+      "strings"
+      "testing"
+)
+
+var update = flag.Bool("update", false, "reescribe los archivos .golden de testdata")
+
+// Ejecuta testdata/ops.jsonl con cada formato y compara la salida con
+// testdata/ops.<formato>.golden. Con -update se reescriben esos archivos.
+func TestBatchGolden(t *testing.T) {
+      script, err := os.ReadFile(filepath.Join("testdata", "ops.jsonl"))
+      if err != nil {
+            t.Fatal(err)
+      }
+      for _, format := range []string{"text", "json", "dot"} {
+            t.Run(format, func(t *testing.T) {
+                  s, err := newSession("int")
+                  if err != nil {
+                        t.Fatal(err)
+                  }
+                  var out bytes.Buffer
+                  failed, err := runBatch(s, bytes.NewReader(script), &out, format)
+                  if err != nil {
+                        t.Fatal(err)
+                  }
+                  // El script tiene tres operaciones inválidas.
+                  if !failed {
+                        t.Error("runBatch no informó las operaciones que fallaron")
+                  }
+
+                  golden := filepath.Join("testdata", "ops."+format+".golden")
+                  if *update {
+                        if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+                              t.Fatal(err)
+                        }
+                  }
+                  want, err := os.ReadFile(golden)
+                  if err != nil {
+                        t.Fatal(err)
+                  }
+                  if !bytes.Equal(out.Bytes(), want) {
+                        t.Errorf("la salida no coincide con %s:\n%s", golden, out.String())
+                  }
+            })
+      }
+}
+
+// Un script sin errores no se informa como fallido, y en JSONL las hileras
+// pueden tener espacios.
+func TestBatchStrings(t *testing.T) {
+      s, err := newSession("string")
+      if err != nil {
+            t.Fatal(err)
+      }
+      script := `{"op": "insert", "values": ["hola mundo", "adiós"]}
+{"op": "find", "value": "hola mundo"}
+quit
+{"op": "insert", "value": "no se ejecuta"}
+`
+      var out bytes.Buffer
+      failed, err := runBatch(s, strings.NewReader(script), &out, "text")
+      if err != nil || failed {
+            t.Fatalf("runBatch = %v, %v", failed, err)
+      }
+      if s.tree.Len() != 2 || !strings.Contains(out.String(), "encontrado (hola mundo : ") {
+            t.Fatalf("salida inesperada:\n%s", out.String())
+      }
+}
+
+func TestBatchUnknownFormat(t *testing.T) {
+      s, _ := newSession("int")
+      if _, err := runBatch(s, strings.NewReader("insert 1\n"), &bytes.Buffer{}, "yaml"); err == nil {
+            t.Fatal("runBatch aceptó un formato desconocido")
+      }
+}
@@ -0,0 +1,57 @@
+/* Programa para explorar el árbol rojinegro desde la línea de comandos.
+   Lee comandos (insert, delete, find, ...) de manera interactiva y los
+   aplica sobre un RBTree de enteros o de hileras. Con -script se ejecuta
+   un archivo de operaciones (texto o JSONL) y se despliega el resultado.
+*/
+
+package main
+
+import (
+      "flag"
+      "fmt"
+      "io"
+      "log/slog"
+      "os"
+)
+
+func main() {
+      cmpName := flag.String("cmp", "int", "comparador de los valores del árbol: int o string")
+      script := flag.String("script", "", "archivo de operaciones por ejecutar (\"-\" para la entrada estándar)")
+      format := flag.String("format", "text", "formato de salida del script: text, json o dot")
+      verbose := flag.Bool("v", false, "despliega en la salida de error los casos por los que pasa el árbol")
+      flag.Parse()
+
+      s, err := newSession(*cmpName)
+      if err != nil {
+            fmt.Fprintln(os.Stderr, "rbtree:", err)
+            os.Exit(2)
+      }
+      if *verbose {
+            s.tree.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+      }
+
+      if *script == "" {
+            repl(s, os.Stdin, os.Stdout)
+            return
+      }
+
+      var in io.Reader = os.Stdin
+      if *script != "-" {
+            f, err := os.Open(*script)
+            if err != nil {
+                  fmt.Fprintln(os.Stderr, "rbtree:", err)
+                  os.Exit(2)
+            }
+            defer f.Close()
+            in = f
+      }
+
+      failed, err := runBatch(s, in, os.Stdout, *format)
+      if err != nil {
+            fmt.Fprintln(os.Stderr, "rbtree:", err)
+            os.Exit(2)
+      }
+      if failed {
+            os.Exit(1)
+      }
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+      "bufio"
+      "fmt"
+      "io"
+      "strconv"
+      "strings"
+)
+
+const prompt = "rbtree> "
+
+// repl lee comandos de in hasta que se termine la entrada o se pida salir,
+// y despliega los resultados y los errores en out. Las líneas ejecutadas se
+// guardan en el historial de la sesión, y "!n" vuelve a ejecutar la línea n.
+func repl(s *session, in io.Reader, out io.Writer) {
+      scanner := bufio.NewScanner(in)
+      fmt.Fprint(out, prompt)
+
+      for scanner.Scan() {
+            line := strings.TrimSpace(scanner.Text())
+
+            if strings.HasPrefix(line, "!") {
+                  n, err := strconv.Atoi(line[1:])
+                  if err != nil || n < 1 || n > len(s.history) {
+                        fmt.Fprintf(out, "error: no existe la entrada %q en el historial\n", line[1:])
+                        fmt.Fprint(out, prompt)
+                        continue
+                  }
+                  line = s.history[n-1]
+                  fmt.Fprintln(out, line)
+            }
+
+            if line == "exit" || line == "quit" {
+                  return
+            }
+            if line != "" {
+                  s.history = append(s.history, line)
+                  if err := s.exec(line, out); err != nil {
+                        fmt.Fprintln(out, "error:", err)
+                  }
+            }
+
+            fmt.Fprint(out, prompt)
+      }
+      fmt.Fprintln(out)
+}
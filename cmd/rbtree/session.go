@@ -0,0 +1,263 @@
+package main
+
+import (
+      "bufio"
+      "fmt"
+      "io"
+      "os"
+      "strconv"
+      "strings"
+
+      redBlackTree "github.com/luahir/Tarea-1-LP"
+)
+
+// La sesión guarda el árbol sobre el que se ejecutan los comandos, la función
+// que convierte los argumentos al tipo de los valores y el historial de líneas.
+type session struct {
+      tree    *redBlackTree.RBTree
+      parse   func(string) (interface{}, error)
+      history []string
+}
+
+// newSession crea una sesión con un árbol vacío que usa IntCmp o StringCmp
+// según cmpName.
+func newSession(cmpName string) (*session, error) {
+      switch cmpName {
+      case "int":
+            return &session{
+                  tree: redBlackTree.NewTree(redBlackTree.IntCmp),
+                  parse: func(arg string) (interface{}, error) {
+                        n, err := strconv.Atoi(arg)
+                        if err != nil {
+                              return nil, fmt.Errorf("%q no es un entero", arg)
+                        }
+                        return n, nil
+                  },
+            }, nil
+      case "string":
+            return &session{
+                  tree: redBlackTree.NewTree(redBlackTree.StringCmp),
+                  parse: func(arg string) (interface{}, error) {
+                        return arg, nil
+                  },
+            }, nil
+      }
+      return nil, fmt.Errorf("comparador desconocido %q (se espera int o string)", cmpName)
+}
+
+// Ayuda que se despliega con el comando help.
+const usage = `Comandos:
+  insert <v>...     inserta los valores
+  delete <v>...     borra los valores
+  find <v>          indica si el valor está en el árbol
+  range <a> <b>     despliega los valores en [a, b]
+  min, max          despliega el valor más pequeño o más grande
+  print             despliega el árbol con su estructura
+  dot               despliega el árbol en formato DOT de Graphviz
+  validate          revisa las condiciones de árbol rojinegro
+  stats             despliega la cantidad de nodos, alturas y profundidades
+  clear             borra todo el árbol
+  load <archivo>    inserta los valores del archivo (uno por línea)
+  save <archivo>    guarda los valores en el archivo (uno por línea)
+  history           despliega las líneas ejecutadas (!n repite la n-ésima)
+  help              despliega esta ayuda
+  exit, quit        termina la sesión`
+
+// exec ejecuta una línea de comando y despliega el resultado en out.
+func (s *session) exec(line string, out io.Writer) error {
+      fields := strings.Fields(line)
+      if len(fields) == 0 {
+            return nil
+      }
+      return s.run(fields[0], fields[1:], out)
+}
+
+// run ejecuta el comando cmd con los argumentos ya separados. Se usa
+// directamente cuando los argumentos no vienen de una línea de texto, por
+// ejemplo en los scripts JSONL, donde una hilera puede contener espacios.
+// Si el árbol entra en pánico se devuelve como error, para que la sesión continúe.
+func (s *session) run(cmd string, args []string, out io.Writer) (err error) {
+      defer func() {
+            if r := recover(); r != nil {
+                  err = fmt.Errorf("error interno del árbol: %v", r)
+            }
+      }()
+
+      switch cmd {
+      case "insert":
+            return s.eachValue(cmd, args, func(v interface{}) {
+                  if !s.tree.Insert(v) {
+                        fmt.Fprintf(out, "%v ya está en el árbol\n", v)
+                  }
+            })
+      case "delete":
+            return s.eachValue(cmd, args, func(v interface{}) {
+                  // Delete no indica si el valor estaba, por lo que se revisa antes.
+                  if !s.tree.FindKey(v) {
+                        fmt.Fprintf(out, "%v no está en el árbol\n", v)
+                        return
+                  }
+                  s.tree.Delete(v)
+            })
+      case "find":
+            if err := wantArgs(cmd, args, 1); err != nil {
+                  return err
+            }
+            v, err := s.parse(args[0])
+            if err != nil {
+                  return err
+            }
+            if found, node := s.tree.Find(v); found {
+                  fmt.Fprintln(out, "encontrado", node)
+            } else {
+                  fmt.Fprintln(out, "no encontrado")
+            }
+      case "range":
+            if err := wantArgs(cmd, args, 2); err != nil {
+                  return err
+            }
+            low, err := s.parse(args[0])
+            if err != nil {
+                  return err
+            }
+            high, err := s.parse(args[1])
+            if err != nil {
+                  return err
+            }
+            values := []string{}
+            for _, node := range s.tree.Range(low, high) {
+                  values = append(values, fmt.Sprint(node.Value()))
+            }
+            fmt.Fprintf(out, "[%s]\n", strings.Join(values, " "))
+      case "min", "max":
+            if err := wantArgs(cmd, args, 0); err != nil {
+                  return err
+            }
+            node := s.tree.Min()
+            if cmd == "max" {
+                  node = s.tree.Max()
+            }
+            if node == nil {
+                  return fmt.Errorf("el árbol está vacío")
+            }
+            fmt.Fprintln(out, node.Value())
+      case "print":
+            s.tree.PrettyFprint(out)
+      case "dot":
+            fmt.Fprint(out, s.tree.Dot())
+      case "validate":
+            if err := s.tree.Validate(); err != nil {
+                  return fmt.Errorf("árbol inválido: %v", err)
+            }
+            fmt.Fprintln(out, "árbol válido")
+      case "stats":
+            if err := wantArgs(cmd, args, 0); err != nil {
+                  return err
+            }
+            stats := s.tree.Stats()
+            fmt.Fprintf(out, "nodos %d (rojos %d, negros %d), altura %d, altura negra %d\n",
+                  stats.Nodes, stats.Red, stats.Black, s.tree.Height(), s.tree.BlackHeight())
+            fmt.Fprintf(out, "profundidad de las hojas entre %d y %d, promedio de los nodos %.2f\n",
+                  stats.MinDepth, stats.MaxDepth, stats.AverageDepth)
+      case "clear":
+            s.tree.Clear()
+      case "load":
+            if err := wantArgs(cmd, args, 1); err != nil {
+                  return err
+            }
+            n, err := s.load(args[0])
+            if err != nil {
+                  return err
+            }
+            fmt.Fprintf(out, "%d valores insertados\n", n)
+      case "save":
+            if err := wantArgs(cmd, args, 1); err != nil {
+                  return err
+            }
+            return s.save(args[0])
+      case "history":
+            for i, h := range s.history {
+                  fmt.Fprintf(out, "%4d  %s\n", i+1, h)
+            }
+      case "help":
+            fmt.Fprintln(out, usage)
+      default:
+            return fmt.Errorf("comando desconocido %q (use help)", cmd)
+      }
+      return nil
+}
+
+// eachValue convierte cada argumento y le aplica fn. Se convierten todos antes
+// de aplicar fn, para que un argumento inválido no deje el comando a medias.
+func (s *session) eachValue(cmd string, args []string, fn func(interface{})) error {
+      if len(args) == 0 {
+            return fmt.Errorf("%s requiere al menos un valor", cmd)
+      }
+      values := make([]interface{}, 0, len(args))
+      for _, arg := range args {
+            v, err := s.parse(arg)
+            if err != nil {
+                  return err
+            }
+            values = append(values, v)
+      }
+      for _, v := range values {
+            fn(v)
+      }
+      return nil
+}
+
+// wantArgs revisa que el comando tenga exactamente n argumentos.
+func wantArgs(cmd string, args []string, n int) error {
+      if len(args) != n {
+            return fmt.Errorf("%s requiere %d argumento(s), se recibieron %d", cmd, n, len(args))
+      }
+      return nil
+}
+
+// load inserta en el árbol los valores del archivo, uno por línea, y devuelve
+// cuántos eran nuevos. Las líneas vacías se ignoran.
+func (s *session) load(path string) (int, error) {
+      f, err := os.Open(path)
+      if err != nil {
+            return 0, err
+      }
+      defer f.Close()
+
+      inserted, lineNum := 0, 0
+      scanner := bufio.NewScanner(f)
+      for scanner.Scan() {
+            lineNum++
+            line := strings.TrimSpace(scanner.Text())
+            if line == "" {
+                  continue
+            }
+            v, err := s.parse(line)
+            if err != nil {
+                  return inserted, fmt.Errorf("%s:%d: %v", path, lineNum, err)
+            }
+            if s.tree.Insert(v) {
+                  inserted++
+            }
+      }
+      return inserted, scanner.Err()
+}
+
+// save escribe los valores del árbol en in-order, uno por línea.
+func (s *session) save(path string) error {
+      f, err := os.Create(path)
+      if err != nil {
+            return err
+      }
+
+      w := bufio.NewWriter(f)
+      iter := &redBlackTree.InorderIterator{}
+      for node := range iter.Iterate(s.tree.Root()) {
+            fmt.Fprintln(w, node.Value())
+      }
+      if err := w.Flush(); err != nil {
+            f.Close()
+            return err
+      }
+      return f.Close()
+}
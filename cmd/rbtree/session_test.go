@@ -0,0 +1,177 @@
+package main
+
+import (
+      "os"
+      "path/filepath"
+      "strings"
+      "testing"
+)
+
+// Cada línea se ejecuta en orden sobre la misma sesión, y se revisa lo que
+// despliega y el error que devuelve.
+func TestExec(t *testing.T) {
+      s, err := newSession("int")
+      if err != nil {
+            t.Fatal(err)
+      }
+      tests := []struct {
+            line, out, err string
+      }{
+            {"", "", ""},
+            {"insert 5 3 8 1", "", ""},
+            {"insert 3", "3 ya está en el árbol\n", ""},
+            {"insert 4 x", "", `"x" no es un entero`},
+            {"find 4", "no encontrado\n", ""},
+            {"find 3", "encontrado (3 : Negro)\n", ""},
+            {"find", "", "find requiere 1 argumento(s), se recibieron 0"},
+            {"range 2 6", "[3 5]\n", ""},
+            {"range 9 20", "[]\n", ""},
+            {"range 2", "", "range requiere 2 argumento(s), se recibieron 1"},
+            {"min", "1\n", ""},
+            {"max", "8\n", ""},
+            {"delete 42", "42 no está en el árbol\n", ""},
+            {"delete 1", "", ""},
+            {"delete", "", "delete requiere al menos un valor"},
+            {"validate", "árbol válido\n", ""},
+            {"print", "(5 : Negro)\n    |-- (3 : Negro)\n    |-- (8 : Negro)\n", ""},
+            // dot debe desplegar lo mismo que Dot sobre el árbol en ese momento.
+            {"dot", "", ""},
+            {"clear", "", ""},
+            {"min", "", "el árbol está vacío"},
+            {"print", "{}\n", ""},
+            {"frobnicate", "", `comando desconocido "frobnicate" (use help)`},
+      }
+      for _, test := range tests {
+            var out strings.Builder
+            err := s.exec(test.line, &out)
+            if test.line == "dot" {
+                  test.out = s.tree.Dot()
+            }
+            if out.String() != test.out {
+                  t.Errorf("%q desplegó %q, se esperaba %q", test.line, out.String(), test.out)
+            }
+            got := ""
+            if err != nil {
+                  got = err.Error()
+            }
+            if got != test.err {
+                  t.Errorf("%q devolvió el error %q, se esperaba %q", test.line, got, test.err)
+            }
+      }
+}
+
+func TestExecStrings(t *testing.T) {
+      s, err := newSession("string")
+      if err != nil {
+            t.Fatal(err)
+      }
+      var out strings.Builder
+      for _, line := range []string{"insert pera manzana uva", "range m q", "min"} {
+            if err := s.exec(line, &out); err != nil {
+                  t.Fatalf("%q: %v", line, err)
+            }
+      }
+      if want := "[manzana pera]\nmanzana\n"; out.String() != want {
+            t.Fatalf("desplegó %q, se esperaba %q", out.String(), want)
+      }
+}
+
+func TestNewSessionUnknownComparator(t *testing.T) {
+      if _, err := newSession("float"); err == nil {
+            t.Fatal("newSession aceptó un comparador desconocido")
+      }
+}
+
+// repl despliega el indicador, los resultados y los errores, guarda el
+// historial y repite líneas con !n.
+func TestRepl(t *testing.T) {
+      s, err := newSession("int")
+      if err != nil {
+            t.Fatal(err)
+      }
+      in := strings.Join([]string{
+            "insert 2 1",
+            "",
+            "find 7",
+            "insert 7",
+            "!2",
+            "!9",
+            "history",
+            "max",
+            "quit",
+            "min",
+      }, "\n")
+      var out strings.Builder
+      repl(s, strings.NewReader(in), &out)
+
+      want := strings.Join([]string{
+            "rbtree> rbtree> rbtree> no encontrado",
+            "rbtree> rbtree> find 7",
+            "encontrado (7 : Rojo)",
+            `rbtree> error: no existe la entrada "9" en el historial`,
+            "rbtree>    1  insert 2 1",
+            "   2  find 7",
+            "   3  insert 7",
+            "   4  find 7",
+            "   5  history",
+            "rbtree> 7",
+            "rbtree> ",
+      }, "\n")
+      if out.String() != want {
+            t.Fatalf("repl desplegó:\n%s\nse esperaba:\n%s", out.String(), want)
+      }
+}
+
+// Al terminar la entrada sin quit se despliega un salto de línea.
+func TestReplEOF(t *testing.T) {
+      s, _ := newSession("int")
+      var out strings.Builder
+      repl(s, strings.NewReader("insert x\n"), &out)
+      want := "rbtree> error: \"x\" no es un entero\nrbtree> \n"
+      if out.String() != want {
+            t.Fatalf("repl desplegó %q, se esperaba %q", out.String(), want)
+      }
+}
+
+func TestSaveLoad(t *testing.T) {
+      dir := t.TempDir()
+      path := filepath.Join(dir, "valores")
+
+      s, _ := newSession("int")
+      var out strings.Builder
+      for _, line := range []string{"insert 3 1 2", "save " + path} {
+            if err := s.exec(line, &out); err != nil {
+                  t.Fatalf("%q: %v", line, err)
+            }
+      }
+      data, err := os.ReadFile(path)
+      if err != nil {
+            t.Fatal(err)
+      }
+      if string(data) != "1\n2\n3\n" {
+            t.Fatalf("save escribió %q", data)
+      }
+
+      // Las líneas vacías se ignoran y los valores repetidos no se cuentan.
+      if err := os.WriteFile(path, []byte("2\n\n  5 \n9\n"), 0o644); err != nil {
+            t.Fatal(err)
+      }
+      out.Reset()
+      if err := s.exec("load "+path, &out); err != nil {
+            t.Fatal(err)
+      }
+      if out.String() != "2 valores insertados\n" {
+            t.Fatalf("load desplegó %q", out.String())
+      }
+
+      if err := os.WriteFile(path, []byte("4\nx\n"), 0o644); err != nil {
+            t.Fatal(err)
+      }
+      err = s.exec("load "+path, &out)
+      if want := path + `:2: "x" no es un entero`; err == nil || err.Error() != want {
+            t.Fatalf("load devolvió %v, se esperaba %q", err, want)
+      }
+      if err := s.exec("load "+filepath.Join(dir, "no-existe"), &out); err == nil {
+            t.Fatal("load de un archivo inexistente no devolvió un error")
+      }
+}
@@ -0,0 +1,43 @@
+/* Servicio HTTP que expone una colección de árboles rojinegros con nombre.
+   Con -trees se crean árboles al iniciar, por ejemplo -trees a:int,b:string;
+   los demás se crean con PUT /trees/{name}. Las rutas se describen en el
+   paquete server.
+*/
+
+package main
+
+import (
+      "flag"
+      "fmt"
+      "net/http"
+      "os"
+      "strings"
+
+      "github.com/luahir/Tarea-1-LP/server"
+)
+
+func main() {
+      addr := flag.String("addr", "localhost:8080", "dirección donde se atienden las peticiones")
+      trees := flag.String("trees", "", "árboles por crear al iniciar, como nombre:tipo separados por comas")
+      flag.Parse()
+
+      s := server.New()
+      if *trees != "" {
+            for _, spec := range strings.Split(*trees, ",") {
+                  name, kind, found := strings.Cut(spec, ":")
+                  if !found {
+                        kind = "int"
+                  }
+                  if err := s.Create(name, kind); err != nil {
+                        fmt.Fprintln(os.Stderr, "rbtreed:", err)
+                        os.Exit(2)
+                  }
+            }
+      }
+
+      fmt.Fprintln(os.Stderr, "rbtreed: escuchando en", *addr)
+      if err := http.ListenAndServe(*addr, s); err != nil {
+            fmt.Fprintln(os.Stderr, "rbtreed:", err)
+            os.Exit(1)
+      }
+}
@@ -0,0 +1,185 @@
+/* Codificación binaria de los valores del árbol.
+   Como los valores son interface{}, cada uno se escribe con una etiqueta de
+   tipo seguida de sus bytes. Se aceptan los tipos básicos que pueden usarse
+   con los comparadores de este paquete y del paquete comparator.
+*/
+
+package redBlackTree
+
+import (
+      "bufio"
+      "encoding/binary"
+      "fmt"
+      "io"
+      "math"
+      "reflect"
+      "unsafe"
+)
+
+// Etiquetas de tipo de los valores codificados.
+const (
+      tagInt byte = iota + 1
+      tagInt64
+      tagUint64
+      tagFloat64
+      tagString
+      tagBytes
+      tagBool
+)
+
+// Largo máximo de una hilera o arreglo de bytes codificado, para no reservar
+// memoria de más al leer datos corruptos.
+const maxEncodedLen = 1 << 30
+
+// ErrUnsupportedValue indica que un valor no puede codificarse en binario.
+type ErrUnsupportedValue struct {
+      Type reflect.Type
+}
+
+func (e *ErrUnsupportedValue) Error() string {
+      return fmt.Sprintf("no se puede codificar un valor de tipo %v", e.Type)
+}
+
+// appendValue agrega a buf la codificación de pValue.
+func appendValue(buf []byte, pValue interface{}) ([]byte, error) {
+      switch v := pValue.(type) {
+      case int:
+            buf = append(buf, tagInt)
+            buf = binary.AppendVarint(buf, int64(v))
+      case int64:
+            buf = append(buf, tagInt64)
+            buf = binary.AppendVarint(buf, v)
+      case uint64:
+            buf = append(buf, tagUint64)
+            buf = binary.AppendUvarint(buf, v)
+      case float64:
+            buf = append(buf, tagFloat64)
+            buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(v))
+      case string:
+            buf = append(buf, tagString)
+            buf = binary.AppendUvarint(buf, uint64(len(v)))
+            buf = append(buf, v...)
+      case []byte:
+            buf = append(buf, tagBytes)
+            buf = binary.AppendUvarint(buf, uint64(len(v)))
+            buf = append(buf, v...)
+      case bool:
+            buf = append(buf, tagBool)
+            if v {
+                  buf = append(buf, 1)
+            } else {
+                  buf = append(buf, 0)
+            }
+      default:
+            return buf, &ErrUnsupportedValue{Type: reflect.TypeOf(pValue)}
+      }
+      return buf, nil
+}
+
+// readValue lee un valor codificado con appendValue.
+func readValue(r *bufio.Reader) (interface{}, error) {
+      tag, err := r.ReadByte()
+      if err != nil {
+            return nil, err
+      }
+
+      switch tag {
+      case tagInt:
+            n, err := binary.ReadVarint(r)
+            return int(n), unexpectedEOF(err)
+      case tagInt64:
+            n, err := binary.ReadVarint(r)
+            return n, unexpectedEOF(err)
+      case tagUint64:
+            n, err := binary.ReadUvarint(r)
+            return n, unexpectedEOF(err)
+      case tagFloat64:
+            var b [8]byte
+            if _, err := io.ReadFull(r, b[:]); err != nil {
+                  return nil, unexpectedEOF(err)
+            }
+            return math.Float64frombits(binary.BigEndian.Uint64(b[:])), nil
+      case tagString, tagBytes:
+            n, err := binary.ReadUvarint(r)
+            if err != nil {
+                  return nil, unexpectedEOF(err)
+            }
+            if n > maxEncodedLen {
+                  return nil, fmt.Errorf("largo inválido %d", n)
+            }
+            b := make([]byte, n)
+            if _, err := io.ReadFull(r, b); err != nil {
+                  return nil, unexpectedEOF(err)
+            }
+            if tag == tagString {
+                  return string(b), nil
+            }
+            return b, nil
+      case tagBool:
+            b, err := r.ReadByte()
+            return b != 0, unexpectedEOF(err)
+      }
+      return nil, fmt.Errorf("etiqueta de tipo desconocida %d", tag)
+}
+
+// unexpectedEOF convierte io.EOF en io.ErrUnexpectedEOF, pues un valor que
+// ya empezó a leerse no puede terminar el archivo.
+func unexpectedEOF(err error) error {
+      if err == io.EOF {
+            return io.ErrUnexpectedEOF
+      }
+      return err
+}
+
+// decodeValue decodifica el valor al inicio de b y devuelve también cuántos
+// bytes ocupa. Si pZeroCopy es true, las hileras y arreglos de bytes apuntan
+// directamente a b en lugar de copiarse, por lo que solamente son válidos
+// mientras b no cambie.
+func decodeValue(b []byte, pZeroCopy bool) (interface{}, int, error) {
+      if len(b) == 0 {
+            return nil, 0, io.ErrUnexpectedEOF
+      }
+
+      switch tag, rest := b[0], b[1:]; tag {
+      case tagInt, tagInt64:
+            n, size := binary.Varint(rest)
+            if size <= 0 {
+                  return nil, 0, io.ErrUnexpectedEOF
+            }
+            if tag == tagInt {
+                  return int(n), 1 + size, nil
+            }
+            return n, 1 + size, nil
+      case tagUint64:
+            n, size := binary.Uvarint(rest)
+            if size <= 0 {
+                  return nil, 0, io.ErrUnexpectedEOF
+            }
+            return n, 1 + size, nil
+      case tagFloat64:
+            if len(rest) < 8 {
+                  return nil, 0, io.ErrUnexpectedEOF
+            }
+            return math.Float64frombits(binary.BigEndian.Uint64(rest)), 9, nil
+      case tagString, tagBytes:
+            n, size := binary.Uvarint(rest)
+            if size <= 0 || n > uint64(len(rest)-size) {
+                  return nil, 0, io.ErrUnexpectedEOF
+            }
+            data := rest[size : size+int(n)]
+            if !pZeroCopy {
+                  data = append([]byte(nil), data...)
+            }
+            if tag == tagString {
+                  return unsafe.String(unsafe.SliceData(data), len(data)), 1 + size + int(n), nil
+            }
+            return data, 1 + size + int(n), nil
+      case tagBool:
+            if len(rest) < 1 {
+                  return nil, 0, io.ErrUnexpectedEOF
+            }
+            return rest[0] != 0, 2, nil
+      default:
+            return nil, 0, fmt.Errorf("etiqueta de tipo desconocida %d", tag)
+      }
+}
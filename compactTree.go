@@ -0,0 +1,577 @@
+/* Árbol rojinegro con almacenamiento compacto.
+   En lugar de un Node por valor con tres punteros, los nodos viven en un
+   arreglo y se refieren unos a otros por su índice (uint32). El color se
+   guarda en el bit menos significativo del índice del padre. Cada nodo ocupa
+   así 32 bytes en una arquitectura de 64 bits (la mitad que un Node), y el
+   recolector de basura no tiene que seguir punteros entre nodos.
+
+   Como no hay objetos Node, CompactTree no ofrece los métodos de RBTree que
+   devuelven nodos; ambos implementan la interfaz Tree, que trabaja con los
+   valores.
+*/
+
+package redBlackTree
+
+import (
+      "fmt"
+      "io"
+      "os"
+      "strings"
+)
+
+// El índice 0 representa al nodo nulo, por lo que nodes[0] nunca se usa.
+const nilIndex uint32 = 0
+
+// Cantidad máxima de nodos, pues se usa un bit del índice del padre para el color.
+const maxCompactNodes = 1<<31 - 1
+
+type compactNode struct {
+      value       interface{}
+      left, right uint32
+      // Índice del padre desplazado un bit, con el color en el bit 0 (1 es negro).
+      parentColor uint32
+}
+
+// El árbol compacto guarda los nodos en un arreglo. Los espacios de los nodos
+// borrados forman una lista libre, enlazada por el campo left.
+type CompactTree struct {
+      nodes []compactNode
+      root  uint32
+      free  uint32
+      cmp   Cmp
+      count int
+}
+
+// Se define un nuevo árbol compacto con un comparador y raíz nula.
+func NewCompactTree(pCmp Cmp) *CompactTree {
+      return &CompactTree{nodes: make([]compactNode, 1), cmp: pCmp}
+}
+
+// Getters y setters por índice. El nodo nulo es negro y no tiene padre.
+
+func (tree *CompactTree) parent(i uint32) uint32 {
+      return tree.nodes[i].parentColor >> 1
+}
+
+func (tree *CompactTree) setParent(i, p uint32) {
+      tree.nodes[i].parentColor = p<<1 | tree.nodes[i].parentColor&1
+}
+
+func (tree *CompactTree) color(i uint32) Color {
+      if i == nilIndex {
+            return NEGRO
+      }
+      return tree.nodes[i].parentColor&1 == 1
+}
+
+func (tree *CompactTree) setColor(i uint32, pColor Color) {
+      if pColor == NEGRO {
+            tree.nodes[i].parentColor |= 1
+      } else {
+            tree.nodes[i].parentColor &^= 1
+      }
+}
+
+// alloc devuelve el índice de un nodo rojo nuevo, tomado de la lista libre o
+// agregado al final del arreglo.
+func (tree *CompactTree) alloc(pValue interface{}, pParent uint32) uint32 {
+      var i uint32
+      if tree.free != nilIndex {
+            i = tree.free
+            tree.free = tree.nodes[i].left
+      } else {
+            if len(tree.nodes) > maxCompactNodes {
+                  panic("El árbol compacto está lleno")
+            }
+            tree.nodes = append(tree.nodes, compactNode{})
+            i = uint32(len(tree.nodes) - 1)
+      }
+      tree.nodes[i] = compactNode{value: pValue, parentColor: pParent << 1}
+      return i
+}
+
+// release limpia el nodo i y lo agrega a la lista libre.
+func (tree *CompactTree) release(i uint32) {
+      tree.nodes[i] = compactNode{left: tree.free}
+      tree.free = i
+}
+
+// lookup busca el índice del nodo con valor igual a pKey, o nilIndex.
+func (tree *CompactTree) lookup(pKey interface{}) uint32 {
+      i := tree.root
+      for i != nilIndex {
+            compare := tree.cmp(pKey, tree.nodes[i].value)
+            switch {
+            case compare < 0:
+                  i = tree.nodes[i].left
+            case compare > 0:
+                  i = tree.nodes[i].right
+            default:
+                  return i
+            }
+      }
+      return nilIndex
+}
+
+// replaceChild pone a newChild en el lugar que ocupaba oldChild bajo pParent.
+func (tree *CompactTree) replaceChild(pParent, oldChild, newChild uint32) {
+      switch {
+      case pParent == nilIndex:
+            tree.root = newChild
+      case tree.nodes[pParent].left == oldChild:
+            tree.nodes[pParent].left = newChild
+      default:
+            tree.nodes[pParent].right = newChild
+      }
+      if newChild != nilIndex {
+            tree.setParent(newChild, pParent)
+      }
+}
+
+// Rotación a la izquierda, como en RBTree.rotLeft.
+func (tree *CompactTree) rotLeft(p uint32) {
+      q := tree.nodes[p].right
+      tree.nodes[p].right = tree.nodes[q].left
+      if tree.nodes[q].left != nilIndex {
+            tree.setParent(tree.nodes[q].left, p)
+      }
+      tree.replaceChild(tree.parent(p), p, q)
+      tree.nodes[q].left = p
+      tree.setParent(p, q)
+}
+
+// Rotación a la derecha, como en RBTree.rotRight.
+func (tree *CompactTree) rotRight(q uint32) {
+      p := tree.nodes[q].left
+      tree.nodes[q].left = tree.nodes[p].right
+      if tree.nodes[p].right != nilIndex {
+            tree.setParent(tree.nodes[p].right, q)
+      }
+      tree.replaceChild(tree.parent(q), q, p)
+      tree.nodes[p].right = q
+      tree.setParent(q, p)
+}
+
+// Insert inserta pValue y devuelve true, o false si ya estaba en el árbol.
+func (tree *CompactTree) Insert(pValue interface{}) bool {
+      parent, i := nilIndex, tree.root
+      compare := 0
+      for i != nilIndex {
+            parent = i
+            compare = tree.cmp(pValue, tree.nodes[i].value)
+            switch {
+            case compare < 0:
+                  i = tree.nodes[i].left
+            case compare > 0:
+                  i = tree.nodes[i].right
+            default:
+                  return false
+            }
+      }
+
+      node := tree.alloc(pValue, parent)
+      switch {
+      case parent == nilIndex:
+            tree.root = node
+      case compare < 0:
+            tree.nodes[parent].left = node
+      default:
+            tree.nodes[parent].right = node
+      }
+      tree.count++
+
+      // Se arreglan las violaciones con los mismos casos que RBTree.Insert.
+      for node != tree.root && tree.color(tree.parent(node)) == ROJO {
+            parent := tree.parent(node)
+            grandpa := tree.parent(parent)
+            if parent == tree.nodes[grandpa].left {
+                  uncle := tree.nodes[grandpa].right
+                  if tree.color(uncle) == ROJO {
+                        tree.setColor(parent, NEGRO)
+                        tree.setColor(uncle, NEGRO)
+                        tree.setColor(grandpa, ROJO)
+                        node = grandpa
+                        continue
+                  }
+                  if node == tree.nodes[parent].right {
+                        tree.rotLeft(parent)
+                        node, parent = parent, node
+                  }
+                  tree.setColor(parent, NEGRO)
+                  tree.setColor(grandpa, ROJO)
+                  tree.rotRight(grandpa)
+            } else {
+                  uncle := tree.nodes[grandpa].left
+                  if tree.color(uncle) == ROJO {
+                        tree.setColor(parent, NEGRO)
+                        tree.setColor(uncle, NEGRO)
+                        tree.setColor(grandpa, ROJO)
+                        node = grandpa
+                        continue
+                  }
+                  if node == tree.nodes[parent].left {
+                        tree.rotRight(parent)
+                        node, parent = parent, node
+                  }
+                  tree.setColor(parent, NEGRO)
+                  tree.setColor(grandpa, ROJO)
+                  tree.rotLeft(grandpa)
+            }
+      }
+      tree.setColor(tree.root, NEGRO)
+      return true
+}
+
+// Delete elimina el valor que coincide con pKey. No hace nada si la llave no existe.
+func (tree *CompactTree) Delete(pKey interface{}) {
+      node := tree.lookup(pKey)
+      if node == nilIndex {
+            return
+      }
+      tree.count--
+
+      // Igual que en RBTree.Delete, child es el nodo que queda en la posición
+      // del que se quita y childParent su padre, pues child puede ser nulo.
+      removedColor := tree.color(node)
+      var child, childParent uint32
+      switch {
+      case tree.nodes[node].left == nilIndex:
+            child, childParent = tree.nodes[node].right, tree.parent(node)
+            tree.replaceChild(childParent, node, child)
+      case tree.nodes[node].right == nilIndex:
+            child, childParent = tree.nodes[node].left, tree.parent(node)
+            tree.replaceChild(childParent, node, child)
+      default:
+            // Se usa el sucesor, el menor valor del subárbol derecho.
+            succ := tree.nodes[node].right
+            for tree.nodes[succ].left != nilIndex {
+                  succ = tree.nodes[succ].left
+            }
+            removedColor = tree.color(succ)
+            child = tree.nodes[succ].right
+            if tree.parent(succ) == node {
+                  childParent = succ
+            } else {
+                  childParent = tree.parent(succ)
+                  tree.replaceChild(childParent, succ, child)
+                  tree.nodes[succ].right = tree.nodes[node].right
+                  tree.setParent(tree.nodes[succ].right, succ)
+            }
+            tree.replaceChild(tree.parent(node), node, succ)
+            tree.nodes[succ].left = tree.nodes[node].left
+            tree.setParent(tree.nodes[succ].left, succ)
+            tree.setColor(succ, tree.color(node))
+      }
+      tree.release(node)
+
+      if removedColor == NEGRO {
+            tree.deleteFix(child, childParent)
+      }
+}
+
+// deleteFix arregla las violaciones del borrado con los mismos casos que
+// RBTree.deleteFix.
+func (tree *CompactTree) deleteFix(node, parent uint32) {
+      for node != tree.root && tree.color(node) == NEGRO {
+            if node == tree.nodes[parent].left {
+                  sibling := tree.nodes[parent].right
+                  if tree.color(sibling) == ROJO {
+                        tree.setColor(sibling, NEGRO)
+                        tree.setColor(parent, ROJO)
+                        tree.rotLeft(parent)
+                        sibling = tree.nodes[parent].right
+                  }
+                  if tree.color(tree.nodes[sibling].left) == NEGRO && tree.color(tree.nodes[sibling].right) == NEGRO {
+                        tree.setColor(sibling, ROJO)
+                        node, parent = parent, tree.parent(parent)
+                        continue
+                  }
+                  if tree.color(tree.nodes[sibling].right) == NEGRO {
+                        tree.setColor(tree.nodes[sibling].left, NEGRO)
+                        tree.setColor(sibling, ROJO)
+                        tree.rotRight(sibling)
+                        sibling = tree.nodes[parent].right
+                  }
+                  tree.setColor(sibling, tree.color(parent))
+                  tree.setColor(parent, NEGRO)
+                  tree.setColor(tree.nodes[sibling].right, NEGRO)
+                  tree.rotLeft(parent)
+            } else {
+                  sibling := tree.nodes[parent].left
+                  if tree.color(sibling) == ROJO {
+                        tree.setColor(sibling, NEGRO)
+                        tree.setColor(parent, ROJO)
+                        tree.rotRight(parent)
+                        sibling = tree.nodes[parent].left
+                  }
+                  if tree.color(tree.nodes[sibling].left) == NEGRO && tree.color(tree.nodes[sibling].right) == NEGRO {
+                        tree.setColor(sibling, ROJO)
+                        node, parent = parent, tree.parent(parent)
+                        continue
+                  }
+                  if tree.color(tree.nodes[sibling].left) == NEGRO {
+                        tree.setColor(tree.nodes[sibling].right, NEGRO)
+                        tree.setColor(sibling, ROJO)
+                        tree.rotLeft(sibling)
+                        sibling = tree.nodes[parent].left
+                  }
+                  tree.setColor(sibling, tree.color(parent))
+                  tree.setColor(parent, NEGRO)
+                  tree.setColor(tree.nodes[sibling].left, NEGRO)
+                  tree.rotRight(parent)
+            }
+            node = tree.root
+      }
+      if node != nilIndex {
+            tree.setColor(node, NEGRO)
+      }
+}
+
+// Get devuelve el valor guardado igual a pKey, o false si no está.
+func (tree *CompactTree) Get(pKey interface{}) (interface{}, bool) {
+      i := tree.lookup(pKey)
+      if i == nilIndex {
+            return nil, false
+      }
+      return tree.nodes[i].value, true
+}
+
+// FindKey indica si pKey está en el árbol.
+func (tree *CompactTree) FindKey(pKey interface{}) bool {
+      return tree.lookup(pKey) != nilIndex
+}
+
+// MinValue devuelve el valor más pequeño, o false si el árbol está vacío.
+func (tree *CompactTree) MinValue() (interface{}, bool) {
+      if tree.root == nilIndex {
+            return nil, false
+      }
+      i := tree.root
+      for tree.nodes[i].left != nilIndex {
+            i = tree.nodes[i].left
+      }
+      return tree.nodes[i].value, true
+}
+
+// MaxValue devuelve el valor más grande, o false si el árbol está vacío.
+func (tree *CompactTree) MaxValue() (interface{}, bool) {
+      if tree.root == nilIndex {
+            return nil, false
+      }
+      i := tree.root
+      for tree.nodes[i].right != nilIndex {
+            i = tree.nodes[i].right
+      }
+      return tree.nodes[i].value, true
+}
+
+// RangeValues devuelve, en orden, los valores en el intervalo cerrado
+// [pLow, pHigh].
+func (tree *CompactTree) RangeValues(pLow, pHigh interface{}) []interface{} {
+      values := []interface{}{}
+
+      var visit func(uint32)
+      visit = func(i uint32) {
+            if i == nilIndex {
+                  return
+            }
+            low, high := tree.cmp(pLow, tree.nodes[i].value), tree.cmp(tree.nodes[i].value, pHigh)
+            if low < 0 {
+                  visit(tree.nodes[i].left)
+            }
+            if low <= 0 && high <= 0 {
+                  values = append(values, tree.nodes[i].value)
+            }
+            if high < 0 {
+                  visit(tree.nodes[i].right)
+            }
+      }
+      visit(tree.root)
+
+      return values
+}
+
+// Each aplica fn a los valores en in-order hasta que fn devuelva false.
+// Usa una pila explícita en lugar de un canal y una goroutine.
+func (tree *CompactTree) Each(fn func(interface{}) bool) {
+      stack := []uint32{}
+      i := tree.root
+      for i != nilIndex || len(stack) > 0 {
+            for i != nilIndex {
+                  stack = append(stack, i)
+                  i = tree.nodes[i].left
+            }
+            i = stack[len(stack)-1]
+            stack = stack[:len(stack)-1]
+            if !fn(tree.nodes[i].value) {
+                  return
+            }
+            i = tree.nodes[i].right
+      }
+}
+
+// Len devuelve la cantidad de valores en el árbol.
+func (tree *CompactTree) Len() int {
+      return tree.count
+}
+
+// Clear borra todo el árbol. Se conserva la capacidad del arreglo, pero se
+// limpian los valores para que el recolector de basura pueda liberarlos.
+func (tree *CompactTree) Clear() {
+      clear(tree.nodes)
+      tree.nodes = tree.nodes[:1]
+      tree.root, tree.free, tree.count = nilIndex, nilIndex, 0
+}
+
+// Despliega los elementos del árbol en in-order, con el mismo formato que RBTree.
+func (tree *CompactTree) String() string {
+      items := []string{}
+      stack := []uint32{}
+      i := tree.root
+      for i != nilIndex || len(stack) > 0 {
+            for i != nilIndex {
+                  stack = append(stack, i)
+                  i = tree.nodes[i].left
+            }
+            i = stack[len(stack)-1]
+            stack = stack[:len(stack)-1]
+            items = append(items, fmt.Sprintf("(%v : %s)", tree.nodes[i].value, tree.color(i)))
+            i = tree.nodes[i].right
+      }
+      return "{" + strings.Join(items, " ") + "}"
+}
+
+// PrettyPrint despliega el árbol con el mismo formato que RBTree.PrettyPrint.
+func (tree *CompactTree) PrettyPrint() {
+      tree.PrettyFprint(os.Stdout)
+}
+
+// PrettyFprint despliega el árbol igual que PrettyPrint, pero en el writer w.
+func (tree *CompactTree) PrettyFprint(w io.Writer) {
+      if tree.root == nilIndex {
+            fmt.Fprintln(w, "{}")
+            return
+      }
+
+      var printChildren func(uint32, string)
+      printChildren = func(i uint32, spaces string) {
+            fmt.Fprintf(w, "(%v : %s)\n", tree.nodes[i].value, tree.color(i))
+            spaces += "    "
+            for _, child := range []uint32{tree.nodes[i].left, tree.nodes[i].right} {
+                  if child != nilIndex {
+                        fmt.Fprint(w, spaces, "|-- ")
+                        printChildren(child, spaces)
+                  }
+            }
+      }
+      printChildren(tree.root, "")
+}
+
+// Dot devuelve el árbol en el lenguaje DOT de Graphviz, con el mismo formato
+// que RBTree.Dot. Los nodos se identifican por su índice.
+func (tree *CompactTree) Dot() string {
+      var b strings.Builder
+      b.WriteString("digraph RBTree {\n")
+      b.WriteString("      node [style=filled, fontcolor=white];\n")
+
+      nils := 0
+      var visit func(uint32)
+      visit = func(i uint32) {
+            fill := "black"
+            if tree.color(i) == ROJO {
+                  fill = "red"
+            }
+            fmt.Fprintf(&b, "      n%d [label=%q, fillcolor=%s];\n", i, fmt.Sprint(tree.nodes[i].value), fill)
+            if p := tree.parent(i); p != nilIndex {
+                  fmt.Fprintf(&b, "      n%d -> n%d;\n", p, i)
+            }
+            for _, child := range []uint32{tree.nodes[i].left, tree.nodes[i].right} {
+                  if child == nilIndex {
+                        fmt.Fprintf(&b, "      nil%d [shape=point, fillcolor=black];\n", nils)
+                        fmt.Fprintf(&b, "      n%d -> nil%d;\n", i, nils)
+                        nils++
+                  }
+            }
+            for _, child := range []uint32{tree.nodes[i].left, tree.nodes[i].right} {
+                  if child != nilIndex {
+                        visit(child)
+                  }
+            }
+      }
+      if tree.root != nilIndex {
+            visit(tree.root)
+      }
+
+      b.WriteString("}\n")
+      return b.String()
+}
+
+// Validate revisa las mismas condiciones que RBTree.Validate.
+func (tree *CompactTree) Validate() error {
+      if tree.root == nilIndex {
+            if tree.count != 0 {
+                  return fmt.Errorf("árbol vacío con contador %d", tree.count)
+            }
+            return nil
+      }
+      if tree.parent(tree.root) != nilIndex {
+            return fmt.Errorf("la raíz %v tiene padre", tree.nodes[tree.root].value)
+      }
+      if tree.color(tree.root) != NEGRO {
+            return fmt.Errorf("la raíz %v no es negra", tree.nodes[tree.root].value)
+      }
+
+      nodes := 0
+      var check func(uint32) (int, error)
+      check = func(i uint32) (int, error) {
+            if i == nilIndex {
+                  return 1, nil
+            }
+            nodes++
+            for _, child := range []uint32{tree.nodes[i].left, tree.nodes[i].right} {
+                  if child == nilIndex {
+                        continue
+                  }
+                  if tree.parent(child) != i {
+                        return 0, fmt.Errorf("el padre de %v no es %v", tree.nodes[child].value, tree.nodes[i].value)
+                  }
+                  if tree.color(i) == ROJO && tree.color(child) == ROJO {
+                        return 0, fmt.Errorf("el nodo rojo %v tiene un hijo rojo %v", tree.nodes[i].value, tree.nodes[child].value)
+                  }
+            }
+            left, err := check(tree.nodes[i].left)
+            if err != nil {
+                  return 0, err
+            }
+            right, err := check(tree.nodes[i].right)
+            if err != nil {
+                  return 0, err
+            }
+            if left != right {
+                  return 0, fmt.Errorf("alturas negras distintas bajo %v: %d y %d", tree.nodes[i].value, left, right)
+            }
+            if tree.color(i) == NEGRO {
+                  left++
+            }
+            return left, nil
+      }
+      if _, err := check(tree.root); err != nil {
+            return err
+      }
+      if nodes != tree.count {
+            return fmt.Errorf("el contador indica %d nodos pero hay %d", tree.count, nodes)
+      }
+
+      var prev interface{}
+      var err error
+      first := true
+      tree.Each(func(v interface{}) bool {
+            if !first && tree.cmp(prev, v) >= 0 {
+                  err = fmt.Errorf("el orden in-order falla entre %v y %v", prev, v)
+                  return false
+            }
+            prev, first = v, false
+            return true
+      })
+      return err
+}
@@ -0,0 +1,217 @@
+package comparator
+
+import (
+      "unicode"
+      "unicode/utf8"
+)
+
+// Comparadores de hileras para texto en lenguaje natural. StringCmp compara
+// bytes, por lo que "Árbol" queda después de "zorro" y "b" después de "Z".
+// Estos comparadores se calculan localmente con tablas propias.
+//
+// Los comparadores que ignoran mayúsculas o tildes consideran iguales hileras
+// distintas ("Árbol" y "arbol"), y el árbol guarda solamente una de ellas. Si
+// se necesitan todas, se usa SpanishCmp, que desempata por tildes y mayúsculas.
+
+// Letras con diacríticos y su letra base. La ñ no se incluye porque en español
+// es una letra aparte; spanishWeight la trata por separado.
+var accentBase = map[rune]rune{}
+
+func init() {
+      groups := []struct {
+            base    rune
+            accents string
+      }{
+            {'a', "áàâäãåāăą"}, {'A', "ÁÀÂÄÃÅĀĂĄ"},
+            {'c', "çćĉċč"}, {'C', "ÇĆĈĊČ"},
+            {'d', "ď"}, {'D', "Ď"},
+            {'e', "éèêëēĕėęě"}, {'E', "ÉÈÊËĒĔĖĘĚ"},
+            {'g', "ĝğġģ"}, {'G', "ĜĞĠĢ"},
+            {'i', "íìîïĩīĭį"}, {'I', "ÍÌÎÏĨĪĬĮİ"},
+            {'l', "ĺļľł"}, {'L', "ĹĻĽŁ"},
+            {'n', "ńņňǹ"}, {'N', "ŃŅŇǸ"},
+            {'o', "óòôöõøōŏő"}, {'O', "ÓÒÔÖÕØŌŎŐ"},
+            {'r', "ŕŗř"}, {'R', "ŔŖŘ"},
+            {'s', "śŝşš"}, {'S', "ŚŜŞŠ"},
+            {'t', "ţť"}, {'T', "ŢŤ"},
+            {'u', "úùûüũūŭůűų"}, {'U', "ÚÙÛÜŨŪŬŮŰŲ"},
+            {'y', "ýÿŷ"}, {'Y', "ÝŸŶ"},
+            {'z', "źżž"}, {'Z', "ŹŻŽ"},
+      }
+      for _, g := range groups {
+            for _, r := range g.accents {
+                  accentBase[r] = g.base
+            }
+      }
+}
+
+// stripAccent devuelve la letra sin diacríticos, o la misma si no tiene.
+func stripAccent(r rune) rune {
+      if base, ok := accentBase[r]; ok {
+            return base
+      }
+      return r
+}
+
+// foldCase lleva la letra a minúscula para compararla sin importar mayúsculas.
+func foldCase(r rune) rune {
+      return unicode.ToLower(unicode.ToUpper(r))
+}
+
+// compareRunes compara dos hileras runa por runa con el peso que asigna
+// weight. Si una es prefijo de la otra, la más corta es menor.
+func compareRunes(s1, s2 string, weight func(rune) int) int {
+      for s1 != "" && s2 != "" {
+            r1, size1 := utf8.DecodeRuneInString(s1)
+            r2, size2 := utf8.DecodeRuneInString(s2)
+            if compare := sign(weight(r1) - weight(r2)); compare != 0 {
+                  return compare
+            }
+            s1, s2 = s1[size1:], s2[size2:]
+      }
+      switch {
+      case s1 != "":
+            return 1
+      case s2 != "":
+            return -1
+      default:
+            return 0
+      }
+}
+
+// CaseFoldCmp compara hileras sin distinguir mayúsculas de minúsculas.
+func CaseFoldCmp(o1, o2 interface{}) int {
+      st1, st2 := o1.(string), o2.(string)
+      return compareRunes(st1, st2, func(r rune) int {
+            return int(foldCase(r))
+      })
+}
+
+// AccentFoldCmp compara hileras sin distinguir tildes ni mayúsculas, de modo
+// que "Árbol" y "arbol" son iguales. La ñ sigue siendo distinta de la n y va
+// entre la n y la o.
+func AccentFoldCmp(o1, o2 interface{}) int {
+      st1, st2 := o1.(string), o2.(string)
+      return compareRunes(st1, st2, spanishWeight)
+}
+
+// spanishWeight ordena las letras como en el alfabeto español: sin importar
+// tildes ni mayúsculas, y con la ñ entre la n y la o. Los pesos se duplican
+// para dejar espacio a la ñ.
+func spanishWeight(r rune) int {
+      r = foldCase(stripAccent(r))
+      if r == 'ñ' {
+            return 2*'n' + 1
+      }
+      return 2 * int(r)
+}
+
+// SpanishCmp ordena hileras según el alfabeto español. Primero se comparan
+// las letras sin tildes ni mayúsculas (con la ñ después de la n); si son
+// iguales, la hilera sin tildes va antes, luego la que tiene minúsculas y
+// por último se comparan los bytes, de modo que solamente hileras idénticas
+// son iguales.
+func SpanishCmp(o1, o2 interface{}) int {
+      st1, st2 := o1.(string), o2.(string)
+
+      if compare := compareRunes(st1, st2, spanishWeight); compare != 0 {
+            return compare
+      }
+      // Desempate por tildes: una letra sin tilde pesa menos.
+      compare := compareRunes(st1, st2, func(r rune) int {
+            if stripAccent(r) != r {
+                  return 1
+            }
+            return 0
+      })
+      if compare != 0 {
+            return compare
+      }
+      // Desempate por mayúsculas: las minúsculas van primero.
+      compare = compareRunes(st1, st2, func(r rune) int {
+            if unicode.IsUpper(r) {
+                  return 1
+            }
+            return 0
+      })
+      if compare != 0 {
+            return compare
+      }
+      return sign(compareBytes(st1, st2))
+}
+
+func compareBytes(st1, st2 string) int {
+      switch {
+      case st1 > st2:
+            return 1
+      case st1 < st2:
+            return -1
+      default:
+            return 0
+      }
+}
+
+// NaturalCmp compara hileras tomando las secuencias de dígitos como números,
+// de modo que "item2" < "item10". Los demás caracteres se comparan como en
+// StringCmp. Si dos números valen lo mismo, va primero el que tiene menos
+// ceros a la izquierda ("a1" < "a01").
+func NaturalCmp(o1, o2 interface{}) int {
+      st1, st2 := o1.(string), o2.(string)
+
+      i, j := 0, 0
+      for i < len(st1) && j < len(st2) {
+            if isDigit(st1[i]) && isDigit(st2[j]) {
+                  // Se toman las dos secuencias completas de dígitos.
+                  end1, end2 := i, j
+                  for end1 < len(st1) && isDigit(st1[end1]) {
+                        end1++
+                  }
+                  for end2 < len(st2) && isDigit(st2[end2]) {
+                        end2++
+                  }
+                  if compare := compareNumbers(st1[i:end1], st2[j:end2]); compare != 0 {
+                        return compare
+                  }
+                  i, j = end1, end2
+                  continue
+            }
+            if st1[i] != st2[j] {
+                  return compareBytes(st1[i:i+1], st2[j:j+1])
+            }
+            i++
+            j++
+      }
+      switch {
+      case i < len(st1):
+            return 1
+      case j < len(st2):
+            return -1
+      default:
+            return 0
+      }
+}
+
+func isDigit(b byte) bool {
+      return '0' <= b && b <= '9'
+}
+
+// compareNumbers compara dos secuencias de dígitos de cualquier largo sin
+// convertirlas a enteros, para que no haya desbordamientos.
+func compareNumbers(n1, n2 string) int {
+      trim1, trim2 := trimZeros(n1), trimZeros(n2)
+      switch {
+      case len(trim1) != len(trim2):
+            return sign(len(trim1) - len(trim2))
+      case trim1 != trim2:
+            return compareBytes(trim1, trim2)
+      default:
+            return sign(len(n1) - len(n2))
+      }
+}
+
+func trimZeros(n string) string {
+      for len(n) > 1 && n[0] == '0' {
+            n = n[1:]
+      }
+      return n
+}
@@ -0,0 +1,70 @@
+package comparator
+
+import (
+      "reflect"
+      "testing"
+
+      redBlackTree "github.com/luahir/Tarea-1-LP"
+)
+
+func TestCaseAndAccentFold(t *testing.T) {
+      runCmpCases(t, []cmpCase{
+            {"CaseFold iguales", CaseFoldCmp, "ÁRBOL", "árbol", 0},
+            {"CaseFold letras", CaseFoldCmp, "b", "A", 1},
+            {"CaseFold tildes", CaseFoldCmp, "arbol", "árbol", -1},
+            {"CaseFold prefijo", CaseFoldCmp, "Casa", "casado", -1},
+            {"AccentFold iguales", AccentFoldCmp, "Árbol", "arbol", 0},
+            {"AccentFold varias tildes", AccentFoldCmp, "canción", "CANCION", 0},
+            {"AccentFold diéresis", AccentFoldCmp, "pingüino", "pinguino", 0},
+            {"AccentFold orden", AccentFoldCmp, "éxito", "zorro", -1},
+            {"AccentFold ñ distinta de n", AccentFoldCmp, "año", "ano", 1},
+            {"AccentFold ñ antes de o", AccentFoldCmp, "ña", "o", -1},
+            {"AccentFold ñ después de n", AccentFoldCmp, "ñ", "nz", 1},
+            {"AccentFold Ñ mayúscula", AccentFoldCmp, "AÑO", "año", 0},
+      })
+}
+
+func TestSpanishCmp(t *testing.T) {
+      runCmpCases(t, []cmpCase{
+            {"iguales", SpanishCmp, "árbol", "árbol", 0},
+            {"sin tilde primero", SpanishCmp, "arbol", "árbol", -1},
+            {"tilde antes que mayúscula", SpanishCmp, "Arbol", "árbol", -1},
+            {"minúscula primero", SpanishCmp, "árbol", "Árbol", -1},
+            {"letras antes que desempates", SpanishCmp, "Árbol", "arbola", -1},
+            {"ñ después de n", SpanishCmp, "ñandú", "nube", 1},
+            {"ñ antes de o", SpanishCmp, "ñandú", "oso", -1},
+      })
+
+      // SpanishCmp es un orden total: el árbol guarda todas las variantes.
+      tree := redBlackTree.NewTree(SpanishCmp)
+      for _, s := range []string{"oso", "Árbol", "nube", "árbol", "ñandú", "arbol", "Arbol", "zorro"} {
+            tree.Insert(s)
+      }
+      want := []string{"arbol", "Arbol", "árbol", "Árbol", "nube", "ñandú", "oso", "zorro"}
+      got := []string{}
+      tree.Each(func(pValue interface{}) bool {
+            got = append(got, pValue.(string))
+            return true
+      })
+      if !reflect.DeepEqual(got, want) {
+            t.Fatalf("orden %q, se esperaba %q", got, want)
+      }
+}
+
+func TestNaturalCmp(t *testing.T) {
+      runCmpCases(t, []cmpCase{
+            {"números", NaturalCmp, "item2", "item10", -1},
+            {"iguales", NaturalCmp, "item10", "item10", 0},
+            {"texto después del número", NaturalCmp, "a1b", "a1c", -1},
+            {"prefijo", NaturalCmp, "a", "a1", -1},
+            {"letra contra dígito", NaturalCmp, "a1", "ab", -1},
+            {"menos ceros primero", NaturalCmp, "a1", "a01", -1},
+            {"ceros y valor", NaturalCmp, "a01", "a2", -1},
+            {"solamente ceros", NaturalCmp, "0", "00", -1},
+            {"ceros en medio", NaturalCmp, "v1.05", "v1.5", 1},
+            {"más largo que int64", NaturalCmp, "x99999999999999999999", "x100000000000000000000", -1},
+            {"largos del mismo largo", NaturalCmp, "123456789012345678901234", "123456789012345678901235", -1},
+            {"largo con ceros", NaturalCmp, "x1", "x00000000000000000000000001", -1},
+            {"largo con ceros y valor", NaturalCmp, "x00000000000000000000000002", "x10", -1},
+      })
+}
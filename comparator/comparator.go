@@ -0,0 +1,187 @@
+/* Biblioteca de comparadores para el árbol rojinegro.
+   Además de IntCmp y StringCmp, que están en el paquete del árbol, se
+   definen comparadores para otros tipos básicos y funciones para combinar
+   comparadores (invertir el orden, comparar por campos, manejar nulos).
+   Todos son de tipo redBlackTree.Cmp, así que pueden pasarse a NewTree.
+*/
+
+package comparator
+
+import (
+      "bytes"
+      "math"
+      "reflect"
+      "time"
+
+      redBlackTree "github.com/luahir/Tarea-1-LP"
+)
+
+// sign convierte el resultado de una comparación en -1, 0 o 1.
+func sign(n int) int {
+      switch {
+      case n > 0:
+            return 1
+      case n < 0:
+            return -1
+      default:
+            return 0
+      }
+}
+
+// Float64Cmp compara valores float64. NaN se considera menor que cualquier
+// otro número e igual a sí mismo, para que el orden sea total; -0 y +0 son iguales.
+func Float64Cmp(o1, o2 interface{}) int {
+      f1, f2 := o1.(float64), o2.(float64)
+
+      nan1, nan2 := math.IsNaN(f1), math.IsNaN(f2)
+      switch {
+      case nan1 && nan2:
+            return 0
+      case nan1:
+            return -1
+      case nan2:
+            return 1
+      case f1 > f2:
+            return 1
+      case f1 < f2:
+            return -1
+      default:
+            return 0
+      }
+}
+
+func Int64Cmp(o1, o2 interface{}) int {
+      n1, n2 := o1.(int64), o2.(int64)
+
+      switch {
+      case n1 > n2:
+            return 1
+      case n1 < n2:
+            return -1
+      default:
+            return 0
+      }
+}
+
+func Uint64Cmp(o1, o2 interface{}) int {
+      n1, n2 := o1.(uint64), o2.(uint64)
+
+      switch {
+      case n1 > n2:
+            return 1
+      case n1 < n2:
+            return -1
+      default:
+            return 0
+      }
+}
+
+// TimeCmp compara instantes de tiempo (time.Time), sin importar su zona horaria.
+func TimeCmp(o1, o2 interface{}) int {
+      t1, t2 := o1.(time.Time), o2.(time.Time)
+      return t1.Compare(t2)
+}
+
+// BytesCmp compara arreglos de bytes en orden lexicográfico. Un arreglo nulo es
+// igual a uno vacío.
+func BytesCmp(o1, o2 interface{}) int {
+      b1, b2 := o1.([]byte), o2.([]byte)
+      return bytes.Compare(b1, b2)
+}
+
+// Reverse devuelve un comparador con el orden inverso de pCmp.
+func Reverse(pCmp redBlackTree.Cmp) redBlackTree.Cmp {
+      return func(o1, o2 interface{}) int {
+            return pCmp(o2, o1)
+      }
+}
+
+// Lexicographic combina varios comparadores sobre los mismos valores: se usa
+// el primero que no los considere iguales. Junto con ByField sirve para ordenar
+// estructuras por varios campos.
+func Lexicographic(pCmps ...redBlackTree.Cmp) redBlackTree.Cmp {
+      return func(o1, o2 interface{}) int {
+            for _, cmp := range pCmps {
+                  if compare := sign(cmp(o1, o2)); compare != 0 {
+                        return compare
+                  }
+            }
+            return 0
+      }
+}
+
+// ByField devuelve un comparador que compara con pCmp el campo que pField
+// extrae de cada valor.
+func ByField(pField func(interface{}) interface{}, pCmp redBlackTree.Cmp) redBlackTree.Cmp {
+      return func(o1, o2 interface{}) int {
+            return pCmp(pField(o1), pField(o2))
+      }
+}
+
+// Tuple compara tuplas representadas como []interface{}, posición por
+// posición, con el comparador correspondiente. Si una tupla es prefijo de la
+// otra, la más corta es menor.
+func Tuple(pCmps ...redBlackTree.Cmp) redBlackTree.Cmp {
+      return func(o1, o2 interface{}) int {
+            t1, t2 := o1.([]interface{}), o2.([]interface{})
+            for i, cmp := range pCmps {
+                  switch {
+                  case i >= len(t1) && i >= len(t2):
+                        return 0
+                  case i >= len(t1):
+                        return -1
+                  case i >= len(t2):
+                        return 1
+                  }
+                  if compare := sign(cmp(t1[i], t2[i])); compare != 0 {
+                        return compare
+                  }
+            }
+            return 0
+      }
+}
+
+// isNil indica si el valor es nulo: la interfaz vacía o un puntero, mapa,
+// canal o función nulos dentro de la interfaz. Un arreglo nulo no se considera
+// nulo, pues comparadores como BytesCmp lo tratan igual que uno vacío.
+func isNil(o interface{}) bool {
+      if o == nil {
+            return true
+      }
+      v := reflect.ValueOf(o)
+      switch v.Kind() {
+      case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func, reflect.Interface:
+            return v.IsNil()
+      }
+      return false
+}
+
+// NullsFirst devuelve un comparador que ordena los valores nulos antes que
+// los demás y compara el resto con pCmp. Los arreglos nulos no son nulos: se
+// comparan con pCmp, así que NullsFirst(BytesCmp) considera iguales nil y []byte{}.
+func NullsFirst(pCmp redBlackTree.Cmp) redBlackTree.Cmp {
+      return nulls(pCmp, -1)
+}
+
+// NullsLast devuelve un comparador que ordena los valores nulos después de
+// los demás y compara el resto con pCmp.
+func NullsLast(pCmp redBlackTree.Cmp) redBlackTree.Cmp {
+      return nulls(pCmp, 1)
+}
+
+// nulls ubica los nulos en el extremo indicado por pSide (-1 al inicio, 1 al final).
+func nulls(pCmp redBlackTree.Cmp, pSide int) redBlackTree.Cmp {
+      return func(o1, o2 interface{}) int {
+            nil1, nil2 := isNil(o1), isNil(o2)
+            switch {
+            case nil1 && nil2:
+                  return 0
+            case nil1:
+                  return pSide
+            case nil2:
+                  return -pSide
+            default:
+                  return pCmp(o1, o2)
+            }
+      }
+}
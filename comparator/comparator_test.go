@@ -0,0 +1,122 @@
+package comparator
+
+import (
+      "math"
+      "testing"
+      "time"
+
+      redBlackTree "github.com/luahir/Tarea-1-LP"
+)
+
+// Caso de prueba de un comparador: el signo esperado de cmp(a, b).
+type cmpCase struct {
+      name string
+      cmp  redBlackTree.Cmp
+      a, b interface{}
+      want int
+}
+
+func runCmpCases(t *testing.T, pCases []cmpCase) {
+      t.Helper()
+      for _, c := range pCases {
+            if got := sign(c.cmp(c.a, c.b)); got != c.want {
+                  t.Errorf("%s: cmp(%v, %v) = %d, se esperaba %d", c.name, c.a, c.b, got, c.want)
+            }
+            // El orden debe ser antisimétrico.
+            if got := sign(c.cmp(c.b, c.a)); got != -c.want {
+                  t.Errorf("%s: cmp(%v, %v) = %d, se esperaba %d", c.name, c.b, c.a, got, -c.want)
+            }
+      }
+}
+
+func TestBasicComparators(t *testing.T) {
+      nan := math.NaN()
+      utc := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+      runCmpCases(t, []cmpCase{
+            {"NaN igual a NaN", Float64Cmp, nan, nan, 0},
+            {"NaN antes de -Inf", Float64Cmp, nan, math.Inf(-1), -1},
+            {"NaN antes de 0", Float64Cmp, nan, 0.0, -1},
+            {"-0 igual a +0", Float64Cmp, math.Copysign(0, -1), 0.0, 0},
+            {"float", Float64Cmp, 1.5, 2.5, -1},
+            {"+Inf", Float64Cmp, math.Inf(1), math.MaxFloat64, 1},
+            {"int64", Int64Cmp, int64(math.MinInt64), int64(0), -1},
+            {"uint64", Uint64Cmp, uint64(math.MaxUint64), uint64(1), 1},
+            {"hora en otra zona", TimeCmp, utc, utc.In(time.FixedZone("CLT", -3*3600)), 0},
+            {"hora", TimeCmp, utc, utc.Add(time.Nanosecond), -1},
+            {"bytes", BytesCmp, []byte("ab"), []byte("b"), -1},
+            {"bytes prefijo", BytesCmp, []byte("ab"), []byte("abc"), -1},
+            {"bytes nil igual a vacío", BytesCmp, []byte(nil), []byte{}, 0},
+      })
+}
+
+// Un árbol con Float64Cmp acepta NaN una sola vez y lo deja al inicio.
+func TestFloat64CmpTree(t *testing.T) {
+      tree := redBlackTree.NewTree(Float64Cmp)
+      for _, f := range []float64{3, math.NaN(), math.Inf(-1), math.NaN(), 0} {
+            tree.Insert(f)
+      }
+      if tree.Len() != 4 {
+            t.Fatalf("Len() = %d, se esperaba 4", tree.Len())
+      }
+      if min, _ := tree.MinValue(); !math.IsNaN(min.(float64)) {
+            t.Fatalf("el mínimo es %v, se esperaba NaN", min)
+      }
+      if err := tree.Validate(); err != nil {
+            t.Fatal(err)
+      }
+}
+
+type person struct {
+      name string
+      age  int
+}
+
+func TestCombinators(t *testing.T) {
+      byAge := ByField(func(o interface{}) interface{} { return o.(person).age }, redBlackTree.IntCmp)
+      byName := ByField(func(o interface{}) interface{} { return o.(person).name }, redBlackTree.StringCmp)
+      byAgeThenName := Lexicographic(byAge, byName)
+      // Un comparador que devuelve magnitudes distintas de 1.
+      wide := func(o1, o2 interface{}) int { return (o1.(int) - o2.(int)) * 10 }
+
+      ana, beto, carla := person{"Ana", 30}, person{"Beto", 25}, person{"Carla", 30}
+      tuple := Tuple(redBlackTree.StringCmp, redBlackTree.IntCmp)
+      runCmpCases(t, []cmpCase{
+            {"Reverse", Reverse(redBlackTree.IntCmp), 1, 2, 1},
+            {"Reverse iguales", Reverse(redBlackTree.IntCmp), 2, 2, 0},
+            {"Reverse dos veces", Reverse(Reverse(redBlackTree.IntCmp)), 1, 2, -1},
+            {"ByField", byAge, beto, ana, -1},
+            {"ByField iguales", byAge, ana, carla, 0},
+            {"Lexicographic primer campo", byAgeThenName, ana, beto, 1},
+            {"Lexicographic segundo campo", byAgeThenName, ana, carla, -1},
+            {"Lexicographic iguales", byAgeThenName, ana, ana, 0},
+            {"Lexicographic normaliza", Lexicographic(wide), 1, 3, -1},
+            {"Tuple primera posición", tuple, []interface{}{"a", 9}, []interface{}{"b", 1}, -1},
+            {"Tuple segunda posición", tuple, []interface{}{"a", 9}, []interface{}{"a", 1}, 1},
+            {"Tuple iguales", tuple, []interface{}{"a", 1}, []interface{}{"a", 1}, 0},
+            {"Tuple prefijo", tuple, []interface{}{"a"}, []interface{}{"a", 1}, -1},
+            {"Tuple vacía", tuple, []interface{}{}, []interface{}{"a"}, -1},
+            {"Tuple normaliza", Tuple(wide), []interface{}{1}, []interface{}{3}, -1},
+      })
+}
+
+func TestNulls(t *testing.T) {
+      var nilPtr *int
+      one := 1
+      ptrCmp := func(o1, o2 interface{}) int {
+            return redBlackTree.IntCmp(*o1.(*int), *o2.(*int))
+      }
+      runCmpCases(t, []cmpCase{
+            {"NullsFirst nil", NullsFirst(redBlackTree.IntCmp), nil, 1, -1},
+            {"NullsLast nil", NullsLast(redBlackTree.IntCmp), nil, 1, 1},
+            {"NullsFirst ambos nil", NullsFirst(redBlackTree.IntCmp), nil, nil, 0},
+            {"NullsFirst resto", NullsFirst(redBlackTree.IntCmp), 2, 1, 1},
+            {"NullsFirst puntero nulo", NullsFirst(ptrCmp), nilPtr, &one, -1},
+            {"NullsLast puntero nulo", NullsLast(ptrCmp), nilPtr, &one, 1},
+            {"NullsFirst puntero nulo y nil", NullsFirst(ptrCmp), nilPtr, nil, 0},
+            // Igual que BytesCmp, un arreglo nulo es igual a uno vacío.
+            {"NullsFirst bytes nil y vacío", NullsFirst(BytesCmp), []byte(nil), []byte{}, 0},
+            {"NullsLast bytes nil y vacío", NullsLast(BytesCmp), []byte(nil), []byte{}, 0},
+            {"NullsFirst bytes nil", NullsFirst(BytesCmp), []byte(nil), []byte("a"), -1},
+            {"NullsFirst bytes y nil", NullsFirst(BytesCmp), nil, []byte(nil), -1},
+      })
+}
@@ -0,0 +1,138 @@
+/* Envoltura del árbol para usarlo desde varias goroutines.
+   El árbol no es seguro para usarse de manera concurrente. ConcurrentTree lo
+   protege con un sync.RWMutex: las consultas pueden ocurrir a la vez y los
+   cambios son exclusivos. Como los nodos pueden cambiar (o reutilizarse)
+   después de soltar el candado, los métodos devuelven valores y no nodos.
+*/
+
+package redBlackTree
+
+import (
+      "sync"
+)
+
+// El árbol concurrente guarda el árbol y su candado.
+type ConcurrentTree struct {
+      mu   sync.RWMutex
+      tree *RBTree
+}
+
+// Se define un nuevo árbol concurrente sobre pTree, que no debe usarse
+// directamente después.
+func NewConcurrentTree(pTree *RBTree) *ConcurrentTree {
+      return &ConcurrentTree{tree: pTree}
+}
+
+// View ejecuta fn con el árbol bloqueado para lectura. fn no debe modificar
+// el árbol ni guardar sus nodos.
+func (ctree *ConcurrentTree) View(fn func(*RBTree)) {
+      ctree.mu.RLock()
+      defer ctree.mu.RUnlock()
+      fn(ctree.tree)
+}
+
+// Update ejecuta fn con el árbol bloqueado para escritura.
+func (ctree *ConcurrentTree) Update(fn func(*RBTree)) {
+      ctree.mu.Lock()
+      defer ctree.mu.Unlock()
+      fn(ctree.tree)
+}
+
+// Insert inserta pValue y devuelve false si ya estaba.
+func (ctree *ConcurrentTree) Insert(pValue interface{}) bool {
+      ctree.mu.Lock()
+      defer ctree.mu.Unlock()
+      return ctree.tree.Insert(pValue)
+}
+
+// Delete borra pKey y devuelve si estaba.
+func (ctree *ConcurrentTree) Delete(pKey interface{}) bool {
+      ctree.mu.Lock()
+      defer ctree.mu.Unlock()
+      if !ctree.tree.FindKey(pKey) {
+            return false
+      }
+      ctree.tree.Delete(pKey)
+      return true
+}
+
+// Clear borra todo el árbol.
+func (ctree *ConcurrentTree) Clear() {
+      ctree.mu.Lock()
+      defer ctree.mu.Unlock()
+      ctree.tree.Clear()
+}
+
+// Find determina si pKey está en el árbol y devuelve el valor guardado.
+func (ctree *ConcurrentTree) Find(pKey interface{}) (bool, interface{}) {
+      ctree.mu.RLock()
+      defer ctree.mu.RUnlock()
+      found, node := ctree.tree.Find(pKey)
+      return found, valueOf(node)
+}
+
+// Min devuelve el valor más pequeño, o false si el árbol está vacío.
+func (ctree *ConcurrentTree) Min() (interface{}, bool) {
+      ctree.mu.RLock()
+      defer ctree.mu.RUnlock()
+      node := ctree.tree.Min()
+      return valueOf(node), node != nil
+}
+
+// Max devuelve el valor más grande, o false si el árbol está vacío.
+func (ctree *ConcurrentTree) Max() (interface{}, bool) {
+      ctree.mu.RLock()
+      defer ctree.mu.RUnlock()
+      node := ctree.tree.Max()
+      return valueOf(node), node != nil
+}
+
+// Range devuelve, en orden, los valores en el intervalo cerrado [pLow, pHigh].
+func (ctree *ConcurrentTree) Range(pLow, pHigh interface{}) []interface{} {
+      ctree.mu.RLock()
+      defer ctree.mu.RUnlock()
+      nodes := ctree.tree.Range(pLow, pHigh)
+      values := make([]interface{}, len(nodes))
+      for i, node := range nodes {
+            values[i] = node.value
+      }
+      return values
+}
+
+// Rank devuelve la cantidad de valores menores que pKey. El árbol debe
+// haberse creado con NewOrderStatisticTree.
+func (ctree *ConcurrentTree) Rank(pKey interface{}) int {
+      ctree.mu.RLock()
+      defer ctree.mu.RUnlock()
+      return ctree.tree.Rank(pKey)
+}
+
+// Select devuelve el i-ésimo valor en orden, o false si no existe. El árbol
+// debe haberse creado con NewOrderStatisticTree.
+func (ctree *ConcurrentTree) Select(i int) (interface{}, bool) {
+      ctree.mu.RLock()
+      defer ctree.mu.RUnlock()
+      node := ctree.tree.Select(i)
+      return valueOf(node), node != nil
+}
+
+// Len devuelve la cantidad de valores en el árbol.
+func (ctree *ConcurrentTree) Len() int {
+      ctree.mu.RLock()
+      defer ctree.mu.RUnlock()
+      return ctree.tree.Len()
+}
+
+// Stats devuelve las medidas de la forma del árbol.
+func (ctree *ConcurrentTree) Stats() Stats {
+      ctree.mu.RLock()
+      defer ctree.mu.RUnlock()
+      return ctree.tree.Stats()
+}
+
+// Dot devuelve el árbol en el formato DOT de Graphviz.
+func (ctree *ConcurrentTree) Dot() string {
+      ctree.mu.RLock()
+      defer ctree.mu.RUnlock()
+      return ctree.tree.Dot()
+}
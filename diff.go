@@ -0,0 +1,113 @@
+/* Diferencias entre dos árboles.
+   Se recorren ambos árboles en in-order a la vez, como al mezclar dos listas
+   ordenadas, así que el costo es O(n + m) y los cambios salen ordenados.
+   Ambos árboles deben usar el mismo orden; se compara con el comparador del
+   primero.
+
+   En modo de conjunto (Diff) un valor está o no está en cada árbol. En modo
+   de mapa (DiffMap) el comparador ordena por llave y los valores con la misma
+   llave se comparan además con una función de igualdad, de modo que una
+   llave cuyo valor cambió se informa como Changed.
+*/
+
+package redBlackTree
+
+// Tipo de un cambio entre dos árboles.
+type ChangeKind int
+
+const (
+      Added ChangeKind = iota + 1
+      Removed
+      Changed
+)
+
+func (pKind ChangeKind) String() string {
+      switch pKind {
+      case Added:
+            return "Agregado"
+      case Removed:
+            return "Quitado"
+      case Changed:
+            return "Cambiado"
+      default:
+            return "Desconocido"
+      }
+}
+
+// Un cambio indica el valor en el primer árbol (Old, nil si se agregó) y en
+// el segundo (New, nil si se quitó).
+type Change struct {
+      Kind ChangeKind
+      Old  interface{}
+      New  interface{}
+}
+
+// next devuelve el nodo siguiente en in-order, o nil si es el último.
+func (pNode *Node) next() *Node {
+      if pNode.right != nil {
+            node := pNode.right
+            for node.left != nil {
+                  node = node.left
+            }
+            return node
+      }
+      node := pNode
+      for node.parent != nil && node.isRight() {
+            node = node.parent
+      }
+      return node.parent
+}
+
+// Diff devuelve, en orden, los valores que están en b y no en a (Added) y
+// los que están en a y no en b (Removed).
+func Diff(a, b *RBTree) []Change {
+      return DiffMap(a, b, nil)
+}
+
+// DiffMap es como Diff, pero además informa como Changed las llaves que están
+// en ambos árboles con valores distintos según pEqual. Con pEqual nil se
+// comporta igual que Diff.
+func DiffMap(a, b *RBTree, pEqual func(o1, o2 interface{}) bool) []Change {
+      changes := []Change{}
+      DiffFunc(a, b, pEqual, func(pChange Change) bool {
+            changes = append(changes, pChange)
+            return true
+      })
+      return changes
+}
+
+// DiffFunc aplica fn, en orden, a cada cambio entre a y b como DiffMap, sin
+// guardarlos, hasta que fn devuelva false.
+func DiffFunc(a, b *RBTree, pEqual func(o1, o2 interface{}) bool, fn func(Change) bool) {
+      nodeA, nodeB := a.Min(), b.Min()
+      for nodeA != nil || nodeB != nil {
+            var change Change
+            compare := 0
+            switch {
+            case nodeA == nil:
+                  compare = 1
+            case nodeB == nil:
+                  compare = -1
+            default:
+                  compare = a.cmp(nodeA.value, nodeB.value)
+            }
+
+            switch {
+            case compare < 0:
+                  change = Change{Kind: Removed, Old: nodeA.value}
+                  nodeA = nodeA.next()
+            case compare > 0:
+                  change = Change{Kind: Added, New: nodeB.value}
+                  nodeB = nodeB.next()
+            default:
+                  if pEqual != nil && !pEqual(nodeA.value, nodeB.value) {
+                        change = Change{Kind: Changed, Old: nodeA.value, New: nodeB.value}
+                  }
+                  nodeA, nodeB = nodeA.next(), nodeB.next()
+            }
+
+            if change.Kind != 0 && !fn(change) {
+                  return
+            }
+      }
+}
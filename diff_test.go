@@ -0,0 +1,79 @@
+package redBlackTree
+
+import (
+      "reflect"
+      "testing"
+)
+
+func newEntryTree(pEntries ...entry) *RBTree {
+      tree := NewTree(entryCmp)
+      for _, e := range pEntries {
+            tree.Insert(e)
+      }
+      return tree
+}
+
+func sameLabel(o1, o2 interface{}) bool {
+      return o1.(entry).label == o2.(entry).label
+}
+
+func TestDiff(t *testing.T) {
+      e1, e2, e3, e4 := entry{1, "a"}, entry{2, "b"}, entry{3, "c"}, entry{4, "d"}
+      e2x := entry{2, "x"}
+      tests := []struct {
+            name    string
+            a, b    []entry
+            diff    []Change
+            diffMap []Change
+      }{
+            {"ambos vacíos", nil, nil, []Change{}, []Change{}},
+            {"vacío contra lleno", nil, []entry{e1, e2},
+                  []Change{{Kind: Added, New: e1}, {Kind: Added, New: e2}},
+                  []Change{{Kind: Added, New: e1}, {Kind: Added, New: e2}}},
+            {"lleno contra vacío", []entry{e1, e2}, nil,
+                  []Change{{Kind: Removed, Old: e1}, {Kind: Removed, Old: e2}},
+                  []Change{{Kind: Removed, Old: e1}, {Kind: Removed, Old: e2}}},
+            {"idénticos", []entry{e1, e2, e3}, []entry{e3, e2, e1}, []Change{}, []Change{}},
+            {"disjuntos intercalados", []entry{e1, e3}, []entry{e2, e4},
+                  []Change{{Kind: Removed, Old: e1}, {Kind: Added, New: e2}, {Kind: Removed, Old: e3}, {Kind: Added, New: e4}},
+                  []Change{{Kind: Removed, Old: e1}, {Kind: Added, New: e2}, {Kind: Removed, Old: e3}, {Kind: Added, New: e4}}},
+            // e2 y e2x son iguales según el comparador: Diff no ve el cambio y
+            // DiffMap lo informa como Changed.
+            {"valor cambiado", []entry{e1, e2, e3}, []entry{e1, e2x, e3},
+                  []Change{},
+                  []Change{{Kind: Changed, Old: e2, New: e2x}}},
+            {"cambiado, agregado y quitado", []entry{e1, e2}, []entry{e2x, e4},
+                  []Change{{Kind: Removed, Old: e1}, {Kind: Added, New: e4}},
+                  []Change{{Kind: Removed, Old: e1}, {Kind: Changed, Old: e2, New: e2x}, {Kind: Added, New: e4}}},
+      }
+      for _, test := range tests {
+            a, b := newEntryTree(test.a...), newEntryTree(test.b...)
+            if got := Diff(a, b); !reflect.DeepEqual(got, test.diff) {
+                  t.Errorf("%s: Diff = %v, se esperaba %v", test.name, got, test.diff)
+            }
+            if got := DiffMap(a, b, sameLabel); !reflect.DeepEqual(got, test.diffMap) {
+                  t.Errorf("%s: DiffMap = %v, se esperaba %v", test.name, got, test.diffMap)
+            }
+            if got := DiffMap(a, b, nil); !reflect.DeepEqual(got, test.diff) {
+                  t.Errorf("%s: DiffMap sin igualdad = %v, se esperaba %v", test.name, got, test.diff)
+            }
+      }
+}
+
+// DiffFunc entrega los mismos cambios que DiffMap y se detiene cuando fn
+// devuelve false.
+func TestDiffFunc(t *testing.T) {
+      a := newEntryTree(entry{1, "a"}, entry{2, "b"}, entry{3, "c"})
+      b := newEntryTree(entry{2, "x"}, entry{3, "c"}, entry{4, "d"}, entry{5, "e"})
+      all := DiffMap(a, b, sameLabel)
+      for limit := 1; limit <= len(all); limit++ {
+            got := []Change{}
+            DiffFunc(a, b, sameLabel, func(pChange Change) bool {
+                  got = append(got, pChange)
+                  return len(got) < limit
+            })
+            if !reflect.DeepEqual(got, all[:limit]) {
+                  t.Fatalf("con límite %d: %v, se esperaba %v", limit, got, all[:limit])
+            }
+      }
+}
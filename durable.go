@@ -0,0 +1,370 @@
+/* Árbol persistente con bitácora de escritura anticipada (WAL).
+   Cada Insert, Delete y Clear se agrega a la bitácora antes de aplicarse al
+   árbol. Cada cierto número de registros se escribe una instantánea con la
+   serialización binaria (WriteTo) y se vacía la bitácora. Al abrir el árbol
+   se lee la instantánea y se repiten los registros de la bitácora.
+
+   Cada registro lleva su largo y un CRC-32 de su contenido. Si el último
+   registro está incompleto o no coincide con su CRC (por ejemplo, porque el
+   proceso murió a media escritura), se descarta y se trunca la bitácora. Un
+   registro completo que no puede aplicarse (por ejemplo, porque el
+   comparador no acepta su valor) no se descarta: Open devuelve un error y
+   deja la bitácora intacta.
+
+   Si escribir o sincronizar un registro falla, la bitácora se trunca al
+   final del último registro completo, para que los registros siguientes no
+   queden después de uno dañado (y se pierdan al abrir). Si ni siquiera puede
+   truncarse, el árbol rechaza todo cambio posterior con ese error.
+
+   Insert, Delete y Clear devuelven un error solamente si el cambio no se
+   aplicó. Si la instantánea automática falla, el cambio ya quedó registrado
+   en la bitácora, así que no se informa como error de la operación: la
+   bitácora sigue creciendo, la instantánea se reintenta en la siguiente
+   escritura y CompactErr devuelve el último error.
+*/
+
+package redBlackTree
+
+import (
+      "bufio"
+      "bytes"
+      "encoding/binary"
+      "errors"
+      "fmt"
+      "hash/crc32"
+      "io"
+      "os"
+      "path/filepath"
+)
+
+// Nombres de los archivos dentro del directorio del árbol.
+const (
+      walFile      = "wal"
+      snapshotFile = "snapshot"
+)
+
+// Operaciones que se registran en la bitácora.
+const (
+      walInsert byte = iota + 1
+      walDelete
+      walClear
+)
+
+// Tamaño del encabezado de cada registro: largo y CRC-32, de 4 bytes cada uno.
+const walHeaderSize = 8
+
+// Opciones de un árbol persistente.
+type DurableOptions struct {
+      // Cantidad de registros en la bitácora a partir de la cual se escribe
+      // una instantánea. Si no es positiva se usa 10000.
+      CompactEvery int
+      // Si es true no se llama a fsync después de cada registro, lo que es
+      // más rápido pero puede perder las últimas operaciones si falla el sistema.
+      NoSync bool
+}
+
+// El árbol persistente guarda el árbol en memoria y la bitácora abierta.
+// Solamente debe modificarse mediante sus métodos, para que todo quede en la
+// bitácora; Tree sirve para las consultas.
+type DurableTree struct {
+      tree    *RBTree
+      dir     string
+      wal     *os.File
+      records int
+      options DurableOptions
+      // Largo de la bitácora hasta el último registro completo.
+      size int64
+      // Error por el que la bitácora quedó inservible, si lo hay.
+      failed error
+      // Error de la última instantánea automática, o nil si tuvo éxito.
+      compactErr error
+}
+
+// Open abre (o crea) el árbol persistente en el directorio pPath, con las
+// opciones por defecto.
+func Open(pPath string, pCmp Cmp) (*DurableTree, error) {
+      return OpenWithOptions(pPath, pCmp, DurableOptions{})
+}
+
+// OpenWithOptions abre (o crea) el árbol persistente en el directorio pPath:
+// lee la instantánea, si existe, y repite los registros válidos de la bitácora.
+func OpenWithOptions(pPath string, pCmp Cmp, pOptions DurableOptions) (*DurableTree, error) {
+      if pOptions.CompactEvery <= 0 {
+            pOptions.CompactEvery = 10000
+      }
+      if err := os.MkdirAll(pPath, 0o755); err != nil {
+            return nil, err
+      }
+
+      tree := NewTree(pCmp)
+      snapshot, err := os.Open(filepath.Join(pPath, snapshotFile))
+      switch {
+      case err == nil:
+            tree, err = ReadTree(snapshot, pCmp)
+            snapshot.Close()
+            if err != nil {
+                  return nil, fmt.Errorf("instantánea inválida: %v", err)
+            }
+      case !errors.Is(err, os.ErrNotExist):
+            return nil, err
+      }
+
+      wal, err := os.OpenFile(filepath.Join(pPath, walFile), os.O_RDWR|os.O_CREATE, 0o644)
+      if err != nil {
+            return nil, err
+      }
+      durable := &DurableTree{tree: tree, dir: pPath, wal: wal, options: pOptions}
+      if err := durable.replay(); err != nil {
+            wal.Close()
+            return nil, err
+      }
+      return durable, nil
+}
+
+// replay aplica los registros de la bitácora al árbol y la trunca después del
+// último registro válido. Repetir registros que ya están en la instantánea no
+// cambia el resultado, pues el estado final de cada valor depende solamente de
+// la última operación que lo afectó.
+func (durable *DurableTree) replay() error {
+      if _, err := durable.wal.Seek(0, io.SeekStart); err != nil {
+            return err
+      }
+      r := bufio.NewReader(durable.wal)
+
+      valid := int64(0)
+      for {
+            payload, err := readFrame(r)
+            if err != nil {
+                  break
+            }
+            if err := durable.apply(payload); err != nil {
+                  return fmt.Errorf("registro %d de la bitácora: %w", durable.records+1, err)
+            }
+            valid += walHeaderSize + int64(len(payload))
+            durable.records++
+      }
+
+      // Lo que sigue al último registro válido es una escritura incompleta.
+      if err := durable.wal.Truncate(valid); err != nil {
+            return err
+      }
+      durable.size = valid
+      _, err := durable.wal.Seek(valid, io.SeekStart)
+      return err
+}
+
+// apply aplica al árbol en memoria la operación de un registro. Usa las
+// operaciones Try, de modo que si el comparador no acepta el valor devuelve
+// un error en lugar de entrar en pánico.
+func (durable *DurableTree) apply(payload []byte) error {
+      switch payload[0] {
+      case walClear:
+            durable.tree.Clear()
+            return nil
+      case walInsert, walDelete:
+            value, err := readValue(bufio.NewReader(bytes.NewReader(payload[1:])))
+            if err != nil {
+                  return err
+            }
+            if payload[0] == walInsert {
+                  _, err = durable.tree.TryInsert(value)
+            } else {
+                  _, err = durable.tree.TryDelete(value)
+            }
+            return err
+      }
+      return fmt.Errorf("operación desconocida %d en la bitácora", payload[0])
+}
+
+// appendRecord agrega un registro a la bitácora y lo sincroniza con el disco.
+// Si falla, deja la bitácora como estaba antes.
+func (durable *DurableTree) appendRecord(op byte, pValue interface{}) error {
+      if durable.failed != nil {
+            return durable.failed
+      }
+      payload := []byte{op}
+      if op != walClear {
+            var err error
+            if payload, err = appendValue(payload, pValue); err != nil {
+                  return err
+            }
+      }
+
+      record := appendFrame(nil, payload)
+      _, err := durable.wal.Write(record)
+      if err == nil && !durable.options.NoSync {
+            err = durable.wal.Sync()
+      }
+      if err != nil {
+            durable.discardTail()
+            return err
+      }
+      durable.size += int64(len(record))
+      durable.records++
+      return nil
+}
+
+// discardTail quita de la bitácora lo que se haya escrito de un registro que
+// falló. Si no lo logra, marca la bitácora como inservible.
+func (durable *DurableTree) discardTail() {
+      err := durable.wal.Truncate(durable.size)
+      if err == nil {
+            _, err = durable.wal.Seek(durable.size, io.SeekStart)
+      }
+      if err != nil {
+            durable.failed = fmt.Errorf("no se pudo reparar la bitácora después de un error: %v", err)
+      }
+}
+
+// appendFrame agrega a buf un registro con el largo y el CRC-32 de payload,
+// seguidos de payload.
+func appendFrame(buf []byte, payload []byte) []byte {
+      buf = binary.BigEndian.AppendUint32(buf, uint32(len(payload)))
+      buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(payload))
+      return append(buf, payload...)
+}
+
+// readFrame lee un registro escrito con appendFrame y devuelve su contenido.
+// Devuelve io.EOF si no había más registros y un error si el registro está
+// incompleto o no coincide con su CRC.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+      header := make([]byte, walHeaderSize)
+      if _, err := io.ReadFull(r, header); err != nil {
+            if err == io.EOF {
+                  return nil, err
+            }
+            return nil, io.ErrUnexpectedEOF
+      }
+      size := binary.BigEndian.Uint32(header[:4])
+      if size == 0 || size > maxEncodedLen {
+            return nil, fmt.Errorf("largo de registro inválido %d", size)
+      }
+      payload := make([]byte, size)
+      if _, err := io.ReadFull(r, payload); err != nil {
+            return nil, io.ErrUnexpectedEOF
+      }
+      if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+            return nil, fmt.Errorf("el CRC del registro no coincide")
+      }
+      return payload, nil
+}
+
+// afterWrite escribe una instantánea si la bitácora ya tiene suficientes
+// registros. El error queda en compactErr, pues el cambio ya se registró.
+func (durable *DurableTree) afterWrite() {
+      if durable.records >= durable.options.CompactEvery {
+            durable.compactErr = durable.Compact()
+      }
+}
+
+// CompactErr devuelve el error de la última instantánea automática, o nil si
+// tuvo éxito o no se ha intentado ninguna.
+func (durable *DurableTree) CompactErr() error {
+      return durable.compactErr
+}
+
+// Insert registra e inserta pValue. Devuelve false si ya estaba en el árbol,
+// en cuyo caso no se registra nada. Si devuelve un error, el valor no se
+// insertó. Antes de registrarlo se busca con TryFind, que compara pValue
+// consigo mismo, para que un valor que el comparador no acepta devuelva un
+// *ErrIncomparable y no quede en la bitácora, donde haría fallar cada Open.
+func (durable *DurableTree) Insert(pValue interface{}) (bool, error) {
+      found, _, err := durable.tree.TryFind(pValue)
+      if err != nil || found {
+            return false, err
+      }
+      if err := durable.appendRecord(walInsert, pValue); err != nil {
+            return false, err
+      }
+      durable.tree.Insert(pValue)
+      durable.afterWrite()
+      return true, nil
+}
+
+// Delete registra y borra pKey. Devuelve false si no estaba en el árbol, en
+// cuyo caso no se registra nada. Si devuelve un error, el valor no se borró.
+// Como en Insert, pKey se busca con TryFind.
+func (durable *DurableTree) Delete(pKey interface{}) (bool, error) {
+      found, _, err := durable.tree.TryFind(pKey)
+      if err != nil || !found {
+            return false, err
+      }
+      if err := durable.appendRecord(walDelete, pKey); err != nil {
+            return false, err
+      }
+      durable.tree.Delete(pKey)
+      durable.afterWrite()
+      return true, nil
+}
+
+// Clear registra y borra todo el árbol. Si devuelve un error, el árbol no
+// se borró.
+func (durable *DurableTree) Clear() error {
+      if err := durable.appendRecord(walClear, nil); err != nil {
+            return err
+      }
+      durable.tree.Clear()
+      durable.afterWrite()
+      return nil
+}
+
+// Tree devuelve el árbol en memoria, para consultarlo. Modificarlo
+// directamente no queda registrado en la bitácora.
+func (durable *DurableTree) Tree() *RBTree {
+      return durable.tree
+}
+
+// Compact escribe una instantánea del árbol y vacía la bitácora. La
+// instantánea se escribe en un archivo temporal que luego se renombra, de
+// modo que siempre existe una instantánea completa.
+func (durable *DurableTree) Compact() error {
+      tmpPath := filepath.Join(durable.dir, snapshotFile+".tmp")
+      tmp, err := os.Create(tmpPath)
+      if err != nil {
+            return err
+      }
+      if _, err := durable.tree.WriteTo(tmp); err != nil {
+            tmp.Close()
+            return err
+      }
+      if err := tmp.Sync(); err != nil {
+            tmp.Close()
+            return err
+      }
+      if err := tmp.Close(); err != nil {
+            return err
+      }
+      if err := os.Rename(tmpPath, filepath.Join(durable.dir, snapshotFile)); err != nil {
+            return err
+      }
+      if err := syncDir(durable.dir); err != nil {
+            return err
+      }
+
+      // Si el proceso muere antes de truncar, al abrir se repiten registros
+      // que ya están en la instantánea, lo que no cambia el resultado.
+      if err := durable.wal.Truncate(0); err != nil {
+            return err
+      }
+      durable.size = 0
+      durable.records = 0
+      if _, err := durable.wal.Seek(0, io.SeekStart); err != nil {
+            return err
+      }
+      return durable.wal.Sync()
+}
+
+// syncDir sincroniza el directorio para que el renombre quede en el disco.
+func syncDir(pDir string) error {
+      dir, err := os.Open(pDir)
+      if err != nil {
+            return err
+      }
+      defer dir.Close()
+      return dir.Sync()
+}
+
+// Close cierra la bitácora. El árbol no debe usarse después.
+func (durable *DurableTree) Close() error {
+      return durable.wal.Close()
+}
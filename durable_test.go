@@ -0,0 +1,334 @@
+package redBlackTree
+
+import (
+      "bytes"
+      "errors"
+      "os"
+      "path/filepath"
+      "reflect"
+      "testing"
+)
+
+// Operación de la secuencia de prueba: inserta v si insert es true, borra v
+// si no, y vacía el árbol si v es negativo.
+type durableOp struct {
+      insert bool
+      v      int
+}
+
+// Cada operación cambia el árbol, así que cada una agrega un registro.
+var durableOps = []durableOp{
+      {true, 5}, {true, 3}, {true, 8}, {false, 3}, {true, 1},
+      {false, -1}, {true, 7}, {true, 2}, {false, 7}, {true, 9},
+}
+
+func applyDurableOp(t *testing.T, durable *DurableTree, op durableOp) {
+      t.Helper()
+      var err error
+      switch {
+      case op.v < 0:
+            err = durable.Clear()
+      case op.insert:
+            _, err = durable.Insert(op.v)
+      default:
+            _, err = durable.Delete(op.v)
+      }
+      if err != nil {
+            t.Fatalf("%+v: %v", op, err)
+      }
+}
+
+// writeDurableOps aplica durableOps en un directorio nuevo y devuelve el
+// contenido de la bitácora, el largo de la bitácora después de cada registro
+// y el contenido del árbol después de cada registro (states[0] es el árbol vacío).
+func writeDurableOps(t *testing.T) (wal []byte, ends []int, states [][]interface{}) {
+      t.Helper()
+      dir := t.TempDir()
+      durable, err := OpenWithOptions(dir, IntCmp, DurableOptions{NoSync: true})
+      if err != nil {
+            t.Fatal(err)
+      }
+      defer durable.Close()
+
+      states = append(states, durable.Tree().contents())
+      for _, op := range durableOps {
+            applyDurableOp(t, durable, op)
+            info, err := os.Stat(filepath.Join(dir, walFile))
+            if err != nil {
+                  t.Fatal(err)
+            }
+            ends = append(ends, int(info.Size()))
+            states = append(states, durable.Tree().contents())
+      }
+      wal, err = os.ReadFile(filepath.Join(dir, walFile))
+      if err != nil {
+            t.Fatal(err)
+      }
+      return wal, ends, states
+}
+
+// openWithWAL abre un árbol en un directorio nuevo cuya bitácora es pWAL.
+func openWithWAL(t *testing.T, pWAL []byte) (*DurableTree, string) {
+      t.Helper()
+      dir := t.TempDir()
+      if err := os.WriteFile(filepath.Join(dir, walFile), pWAL, 0o644); err != nil {
+            t.Fatal(err)
+      }
+      durable, err := OpenWithOptions(dir, IntCmp, DurableOptions{NoSync: true})
+      if err != nil {
+            t.Fatal(err)
+      }
+      return durable, dir
+}
+
+func checkDurable(t *testing.T, durable *DurableTree, pWant []interface{}) {
+      t.Helper()
+      if err := durable.Tree().Validate(); err != nil {
+            t.Fatal(err)
+      }
+      if got := durable.Tree().contents(); !reflect.DeepEqual(got, pWant) {
+            t.Fatalf("el árbol tiene %v, se esperaba %v", got, pWant)
+      }
+}
+
+// Una bitácora cortada en cualquier byte conserva los registros completos, y
+// lo que se escribe después no se pierde al volver a abrir.
+func TestDurableTornTail(t *testing.T) {
+      wal, ends, states := writeDurableOps(t)
+
+      for offset := 0; offset <= len(wal); offset++ {
+            complete := 0
+            for complete < len(ends) && ends[complete] <= offset {
+                  complete++
+            }
+            durable, dir := openWithWAL(t, wal[:offset])
+            checkDurable(t, durable, states[complete])
+
+            if _, err := durable.Insert(100); err != nil {
+                  t.Fatalf("byte %d: %v", offset, err)
+            }
+            durable.Close()
+            durable, err := OpenWithOptions(dir, IntCmp, DurableOptions{NoSync: true})
+            if err != nil {
+                  t.Fatalf("byte %d: %v", offset, err)
+            }
+            want := append(append([]interface{}{}, states[complete]...), 100)
+            checkDurable(t, durable, want)
+            durable.Close()
+      }
+}
+
+// Si cualquier byte de un registro está dañado, se descartan ese registro y
+// los siguientes.
+func TestDurableCorruptRecord(t *testing.T) {
+      wal, ends, states := writeDurableOps(t)
+
+      start := 0
+      for record, end := range ends {
+            for offset := start; offset < end; offset++ {
+                  corrupt := append([]byte{}, wal...)
+                  corrupt[offset] ^= 0x40
+                  durable, _ := openWithWAL(t, corrupt)
+                  checkDurable(t, durable, states[record])
+                  durable.Close()
+            }
+            start = end
+      }
+}
+
+// Al abrir de nuevo se repiten los registros, con o sin instantánea.
+func TestDurableReplay(t *testing.T) {
+      tests := []struct {
+            name    string
+            options DurableOptions
+      }{
+            {"sin instantánea", DurableOptions{NoSync: true}},
+            {"con instantánea", DurableOptions{CompactEvery: 3, NoSync: true}},
+            {"con fsync", DurableOptions{CompactEvery: 4}},
+      }
+      for _, test := range tests {
+            t.Run(test.name, func(t *testing.T) {
+                  dir := t.TempDir()
+                  durable, err := OpenWithOptions(dir, IntCmp, test.options)
+                  if err != nil {
+                        t.Fatal(err)
+                  }
+                  for _, op := range durableOps {
+                        applyDurableOp(t, durable, op)
+                  }
+                  want := durable.Tree().contents()
+                  durable.Close()
+
+                  for i := 0; i < 2; i++ {
+                        durable, err = OpenWithOptions(dir, IntCmp, test.options)
+                        if err != nil {
+                              t.Fatal(err)
+                        }
+                        checkDurable(t, durable, want)
+                        durable.Close()
+                  }
+            })
+      }
+}
+
+// Compact deja todo en la instantánea y vacía la bitácora.
+func TestDurableCompact(t *testing.T) {
+      dir := t.TempDir()
+      durable, err := OpenWithOptions(dir, IntCmp, DurableOptions{NoSync: true})
+      if err != nil {
+            t.Fatal(err)
+      }
+      for _, op := range durableOps {
+            applyDurableOp(t, durable, op)
+      }
+      if err := durable.Compact(); err != nil {
+            t.Fatal(err)
+      }
+      info, err := os.Stat(filepath.Join(dir, walFile))
+      if err != nil {
+            t.Fatal(err)
+      }
+      if info.Size() != 0 {
+            t.Fatalf("la bitácora tiene %d bytes después de Compact", info.Size())
+      }
+      if _, err := durable.Insert(50); err != nil {
+            t.Fatal(err)
+      }
+      want := durable.Tree().contents()
+      durable.Close()
+
+      durable, err = OpenWithOptions(dir, IntCmp, DurableOptions{NoSync: true})
+      if err != nil {
+            t.Fatal(err)
+      }
+      defer durable.Close()
+      checkDurable(t, durable, want)
+}
+
+// Si la instantánea automática falla, el cambio se aplica igual y la
+// instantánea se reintenta en la siguiente escritura.
+func TestDurableCompactFailure(t *testing.T) {
+      dir := t.TempDir()
+      durable, err := OpenWithOptions(dir, IntCmp, DurableOptions{CompactEvery: 2, NoSync: true})
+      if err != nil {
+            t.Fatal(err)
+      }
+      defer durable.Close()
+
+      // Un directorio con el nombre del archivo temporal impide crearlo.
+      blocker := filepath.Join(dir, snapshotFile+".tmp")
+      if err := os.Mkdir(blocker, 0o755); err != nil {
+            t.Fatal(err)
+      }
+      for _, v := range []int{1, 2, 3} {
+            if inserted, err := durable.Insert(v); !inserted || err != nil {
+                  t.Fatalf("Insert(%d) = %v, %v", v, inserted, err)
+            }
+      }
+      if durable.CompactErr() == nil {
+            t.Fatal("CompactErr no informa el error de la instantánea")
+      }
+
+      if err := os.Remove(blocker); err != nil {
+            t.Fatal(err)
+      }
+      if _, err := durable.Insert(4); err != nil {
+            t.Fatal(err)
+      }
+      if err := durable.CompactErr(); err != nil {
+            t.Fatalf("la instantánea no se reintentó: %v", err)
+      }
+      checkDurable(t, durable, []interface{}{1, 2, 3, 4})
+}
+
+// Si la bitácora no se puede escribir, el cambio no se aplica y el árbol
+// rechaza los cambios siguientes.
+func TestDurableWriteFailure(t *testing.T) {
+      dir := t.TempDir()
+      durable, err := OpenWithOptions(dir, IntCmp, DurableOptions{NoSync: true})
+      if err != nil {
+            t.Fatal(err)
+      }
+      for _, v := range []int{1, 2} {
+            if _, err := durable.Insert(v); err != nil {
+                  t.Fatal(err)
+            }
+      }
+      durable.wal.Close()
+
+      if inserted, err := durable.Insert(3); inserted || err == nil {
+            t.Fatalf("Insert = %v, %v; se esperaba un error", inserted, err)
+      }
+      if deleted, err := durable.Delete(1); deleted || err == nil {
+            t.Fatalf("Delete = %v, %v; se esperaba un error", deleted, err)
+      }
+      if err := durable.Clear(); err == nil {
+            t.Fatal("Clear no devolvió un error")
+      }
+      checkDurable(t, durable, []interface{}{1, 2})
+
+      durable, err = OpenWithOptions(dir, IntCmp, DurableOptions{NoSync: true})
+      if err != nil {
+            t.Fatal(err)
+      }
+      defer durable.Close()
+      checkDurable(t, durable, []interface{}{1, 2})
+}
+
+// Un valor que el comparador no acepta se rechaza con un error, aunque el
+// árbol esté vacío, y no queda en la bitácora.
+func TestDurableRejectsIncomparable(t *testing.T) {
+      dir := t.TempDir()
+      durable, err := OpenWithOptions(dir, IntCmp, DurableOptions{NoSync: true})
+      if err != nil {
+            t.Fatal(err)
+      }
+      for _, tree := range []string{"vacío", "con valores"} {
+            var incomparable *ErrIncomparable
+            if inserted, err := durable.Insert("x"); inserted || !errors.As(err, &incomparable) {
+                  t.Fatalf("árbol %s: Insert(\"x\") = %v, %v", tree, inserted, err)
+            }
+            if deleted, err := durable.Delete("x"); deleted || !errors.As(err, &incomparable) {
+                  t.Fatalf("árbol %s: Delete(\"x\") = %v, %v", tree, deleted, err)
+            }
+            if _, err := durable.Insert(1); err != nil {
+                  t.Fatal(err)
+            }
+      }
+      durable.Close()
+
+      durable, err = OpenWithOptions(dir, IntCmp, DurableOptions{NoSync: true})
+      if err != nil {
+            t.Fatal(err)
+      }
+      defer durable.Close()
+      checkDurable(t, durable, []interface{}{1})
+}
+
+// Si la bitácora tiene un registro que el comparador no acepta, Open devuelve
+// un error en lugar de entrar en pánico y no trunca la bitácora.
+func TestDurableReplayIncomparable(t *testing.T) {
+      dir := t.TempDir()
+      durable, err := OpenWithOptions(dir, StringCmp, DurableOptions{NoSync: true})
+      if err != nil {
+            t.Fatal(err)
+      }
+      for _, s := range []string{"a", "b"} {
+            if _, err := durable.Insert(s); err != nil {
+                  t.Fatal(err)
+            }
+      }
+      durable.Close()
+      wal, err := os.ReadFile(filepath.Join(dir, walFile))
+      if err != nil {
+            t.Fatal(err)
+      }
+
+      var incomparable *ErrIncomparable
+      if _, err := OpenWithOptions(dir, IntCmp, DurableOptions{NoSync: true}); !errors.As(err, &incomparable) {
+            t.Fatalf("Open = %v, se esperaba un *ErrIncomparable", err)
+      }
+      if after, err := os.ReadFile(filepath.Join(dir, walFile)); err != nil || !bytes.Equal(after, wal) {
+            t.Fatalf("la bitácora cambió al fallar Open: %v", err)
+      }
+}
@@ -0,0 +1,145 @@
+/* Notificaciones de los cambios del árbol.
+   Con Subscribe se registra una función que recibe un Event después de cada
+   Insert, Delete, Replace y Clear, cuando el árbol ya cumple de nuevo con
+   todas las reglas. SubscribeChan entrega los eventos por un canal con un
+   búfer limitado, para consumirlos desde otra goroutine sin detener el árbol.
+*/
+
+package redBlackTree
+
+import (
+      "sync"
+)
+
+// Tipo de un evento.
+type EventKind int
+
+const (
+      Inserted EventKind = iota + 1
+      Deleted
+      Cleared
+      Replaced
+      // Se perdieron eventos porque el búfer de un canal estaba lleno. Value
+      // tiene la cantidad (int) de eventos perdidos.
+      Overflowed
+)
+
+func (pKind EventKind) String() string {
+      switch pKind {
+      case Inserted:
+            return "Insertado"
+      case Deleted:
+            return "Borrado"
+      case Cleared:
+            return "Vaciado"
+      case Replaced:
+            return "Reemplazado"
+      case Overflowed:
+            return "Desbordado"
+      default:
+            return "Desconocido"
+      }
+}
+
+// Un evento indica el cambio y el valor afectado. En Replaced, Old es el
+// valor que se reemplazó y Value el nuevo; en Cleared ambos son nil.
+type Event struct {
+      Kind  EventKind
+      Value interface{}
+      Old   interface{}
+}
+
+// Suscriptores del árbol. El mutex permite cancelar una suscripción desde
+// otra goroutine mientras el árbol notifica.
+type observers struct {
+      mu   sync.Mutex
+      subs []*func(Event)
+}
+
+// Subscribe registra fn para que reciba los eventos del árbol, en la misma
+// goroutine que hace el cambio. fn no debe modificar el árbol. Devuelve la
+// función que cancela la suscripción.
+func (tree *RBTree) Subscribe(fn func(Event)) func() {
+      sub := &fn
+      tree.observers.mu.Lock()
+      tree.observers.subs = append(tree.observers.subs, sub)
+      tree.observers.mu.Unlock()
+
+      return func() {
+            tree.observers.mu.Lock()
+            defer tree.observers.mu.Unlock()
+            for i, s := range tree.observers.subs {
+                  if s == sub {
+                        tree.observers.subs = append(tree.observers.subs[:i:i], tree.observers.subs[i+1:]...)
+                        return
+                  }
+            }
+      }
+}
+
+// notify entrega pEvent a todos los suscriptores.
+func (tree *RBTree) notify(pEvent Event) {
+      tree.observers.mu.Lock()
+      subs := tree.observers.subs
+      tree.observers.mu.Unlock()
+      for _, sub := range subs {
+            (*sub)(pEvent)
+      }
+}
+
+// Suscripción por canal. lost cuenta los eventos que no cupieron en el búfer
+// desde el último Overflowed enviado.
+type chanSubscriber struct {
+      mu     sync.Mutex
+      ch     chan Event
+      lost   int
+      closed bool
+}
+
+func (sub *chanSubscriber) send(pEvent Event) {
+      sub.mu.Lock()
+      defer sub.mu.Unlock()
+      if sub.closed {
+            return
+      }
+      // Antes del evento se avisa cuántos se perdieron, si hay espacio.
+      if sub.lost > 0 {
+            select {
+            case sub.ch <- Event{Kind: Overflowed, Value: sub.lost}:
+                  sub.lost = 0
+            default:
+                  sub.lost++
+                  return
+            }
+      }
+      select {
+      case sub.ch <- pEvent:
+      default:
+            sub.lost++
+      }
+}
+
+// SubscribeChan entrega los eventos del árbol por un canal con búfer de
+// pSize eventos. El árbol nunca espera al consumidor: si el búfer está lleno
+// el evento se pierde, y en cuanto haya espacio se envía un evento
+// Overflowed con la cantidad perdida, para que el consumidor sepa que debe
+// volver a leer el árbol. La función devuelta cancela la suscripción y
+// cierra el canal.
+func (tree *RBTree) SubscribeChan(pSize int) (<-chan Event, func()) {
+      if pSize < 1 {
+            pSize = 1
+      }
+      sub := &chanSubscriber{ch: make(chan Event, pSize)}
+      unsubscribe := tree.Subscribe(sub.send)
+
+      var once sync.Once
+      return sub.ch, func() {
+            once.Do(func() {
+                  unsubscribe()
+                  sub.mu.Lock()
+                  sub.closed = true
+                  close(sub.ch)
+                  sub.mu.Unlock()
+            })
+      }
+}
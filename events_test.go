@@ -0,0 +1,118 @@
+package redBlackTree
+
+import (
+      "reflect"
+      "testing"
+)
+
+// Cada cambio produce un evento, en orden y con el árbol ya balanceado; los
+// que no cambian nada no producen ninguno.
+func TestEventsOrder(t *testing.T) {
+      tree := NewTree(entryCmp)
+      events := []Event{}
+      tree.Subscribe(func(pEvent Event) {
+            if err := tree.Validate(); err != nil {
+                  t.Fatalf("%v: %v", pEvent, err)
+            }
+            events = append(events, pEvent)
+      })
+
+      tree.Insert(entry{2, "dos"})
+      tree.Insert(entry{1, "uno"})
+      tree.Insert(entry{3, "tres"})
+      tree.Insert(entry{1, "otro"})
+      tree.Replace(entry{2, "DOS"})
+      tree.Replace(entry{5, "cinco"})
+      tree.Delete(entry{key: 1})
+      tree.Delete(entry{key: 1})
+      tree.Clear()
+
+      want := []Event{
+            {Kind: Inserted, Value: entry{2, "dos"}},
+            {Kind: Inserted, Value: entry{1, "uno"}},
+            {Kind: Inserted, Value: entry{3, "tres"}},
+            {Kind: Replaced, Value: entry{2, "DOS"}, Old: entry{2, "dos"}},
+            // Delete informa el valor que estaba en el árbol, no la llave.
+            {Kind: Deleted, Value: entry{1, "uno"}},
+            {Kind: Cleared},
+      }
+      if !reflect.DeepEqual(events, want) {
+            t.Fatalf("eventos %v, se esperaba %v", events, want)
+      }
+}
+
+func TestEventsUnsubscribe(t *testing.T) {
+      tree := NewTree(IntCmp)
+      first, second := 0, 0
+      cancelFirst := tree.Subscribe(func(Event) { first++ })
+      tree.Subscribe(func(Event) { second++ })
+
+      tree.Insert(1)
+      cancelFirst()
+      tree.Insert(2)
+      // Cancelar dos veces no afecta a los demás suscriptores.
+      cancelFirst()
+      tree.Delete(1)
+      if first != 1 || second != 3 {
+            t.Fatalf("el primero recibió %d eventos y el segundo %d", first, second)
+      }
+}
+
+// receive lee los eventos que hay en el canal sin esperar.
+func receive(ch <-chan Event) []Event {
+      events := []Event{}
+      for {
+            select {
+            case event := <-ch:
+                  events = append(events, event)
+            default:
+                  return events
+            }
+      }
+}
+
+// Con el búfer lleno los eventos se pierden, y en cuanto hay espacio llega un
+// Overflowed con la cantidad perdida, antes del evento siguiente.
+func TestEventsOverflow(t *testing.T) {
+      tree := NewTree(IntCmp)
+      ch, cancel := tree.SubscribeChan(2)
+      for i := 1; i <= 5; i++ {
+            tree.Insert(i)
+      }
+      want := []Event{{Kind: Inserted, Value: 1}, {Kind: Inserted, Value: 2}}
+      if got := receive(ch); !reflect.DeepEqual(got, want) {
+            t.Fatalf("eventos %v, se esperaba %v", got, want)
+      }
+
+      tree.Insert(6)
+      want = []Event{{Kind: Overflowed, Value: 3}, {Kind: Inserted, Value: 6}}
+      if got := receive(ch); !reflect.DeepEqual(got, want) {
+            t.Fatalf("eventos %v, se esperaba %v", got, want)
+      }
+
+      // Si solamente cabe el Overflowed, el evento siguiente se cuenta para
+      // el próximo aviso, igual que los que llegan con el búfer lleno.
+      tree.Insert(7)
+      tree.Insert(8)
+      tree.Insert(9)
+      <-ch
+      tree.Insert(10)
+      tree.Delete(10)
+      want = []Event{{Kind: Inserted, Value: 8}, {Kind: Overflowed, Value: 1}}
+      if got := receive(ch); !reflect.DeepEqual(got, want) {
+            t.Fatalf("eventos %v, se esperaba %v", got, want)
+      }
+      tree.Insert(11)
+      want = []Event{{Kind: Overflowed, Value: 2}, {Kind: Inserted, Value: 11}}
+      if got := receive(ch); !reflect.DeepEqual(got, want) {
+            t.Fatalf("eventos %v, se esperaba %v", got, want)
+      }
+
+      // Al cancelar se cierra el canal y los cambios siguientes no fallan.
+      cancel()
+      cancel()
+      tree.Clear()
+      if _, ok := <-ch; ok {
+            t.Fatal("el canal sigue abierto después de cancelar")
+      }
+}
@@ -0,0 +1,247 @@
+/* Archivo de solo lectura con los valores de un árbol, para consultarlo sin
+   construir nodos. Los valores se guardan ordenados, así que el arreglo es
+   un árbol implícito: la raíz es el valor del centro y los hijos de cada
+   rango son los centros de sus mitades, que es lo que recorre la búsqueda
+   binaria. El archivo se abre con mmap donde el sistema lo permite.
+
+   Formato:
+      "RBF1" | valores codificados | desplazamientos | cantidad | inicio | "RBF1"
+   Hay un desplazamiento (uint64) por valor más uno final con el fin de los
+   datos, todos relativos al inicio del archivo. Cantidad e inicio (de la
+   tabla de desplazamientos) también son uint64. Todo número fijo va en big endian.
+
+   Al abrir el archivo se revisan el formato y cada uno de los valores, así
+   que las consultas no pueden encontrarse con un valor dañado. El archivo
+   se escribe en uno temporal que se renombra al terminar, de modo que nunca
+   queda a medio escribir.
+*/
+
+package redBlackTree
+
+import (
+      "bufio"
+      "encoding/binary"
+      "fmt"
+      "os"
+      "path/filepath"
+      "sort"
+)
+
+const (
+      flatMagic      = "RBF1"
+      flatFooterSize = 8 + 8 + len(flatMagic)
+)
+
+// WriteFlatFile escribe en pPath los valores del árbol, en in-order, con el
+// formato que lee OpenFlatFile. Si falla, pPath queda como estaba.
+func WriteFlatFile(pPath string, tree *RBTree) error {
+      f, err := os.CreateTemp(filepath.Dir(pPath), filepath.Base(pPath)+".tmp*")
+      if err != nil {
+            return err
+      }
+      if err := writeFlat(f, tree); err != nil {
+            f.Close()
+            os.Remove(f.Name())
+            return err
+      }
+      if err := f.Close(); err != nil {
+            os.Remove(f.Name())
+            return err
+      }
+      if err := os.Rename(f.Name(), pPath); err != nil {
+            os.Remove(f.Name())
+            return err
+      }
+      return nil
+}
+
+// writeFlat escribe los valores del árbol en f y lo sincroniza con el disco.
+func writeFlat(f *os.File, tree *RBTree) error {
+      if err := f.Chmod(0o644); err != nil {
+            return err
+      }
+      w := bufio.NewWriter(f)
+      var err error
+
+      offsets := make([]uint64, 0, tree.count+1)
+      offset := uint64(len(flatMagic))
+      buf := []byte{}
+      w.WriteString(flatMagic)
+
+      iter := &InorderIterator{}
+      ch := iter.Iterate(tree.root)
+      for node := range ch {
+            if buf, err = appendValue(buf[:0], node.value); err != nil {
+                  for range ch {
+                  }
+                  return err
+            }
+            offsets = append(offsets, offset)
+            offset += uint64(len(buf))
+            w.Write(buf)
+      }
+      offsets = append(offsets, offset)
+
+      for _, o := range offsets {
+            buf = binary.BigEndian.AppendUint64(buf[:0], o)
+            w.Write(buf)
+      }
+      buf = binary.BigEndian.AppendUint64(buf[:0], uint64(len(offsets)-1))
+      buf = binary.BigEndian.AppendUint64(buf, offset)
+      buf = append(buf, flatMagic...)
+      w.Write(buf)
+
+      // bufio.Writer guarda el primer error, así que basta con revisar Flush.
+      if err := w.Flush(); err != nil {
+            return err
+      }
+      return f.Sync()
+}
+
+// El árbol plano lee los valores directamente de los bytes del archivo. Las
+// hileras y arreglos de bytes que devuelve apuntan a esos bytes, así que no
+// deben modificarse ni usarse después de Close.
+type FlatTree struct {
+      data    []byte
+      offsets []byte
+      count   int
+      cmp     Cmp
+      release func() error
+}
+
+// OpenFlatFile abre un archivo escrito con WriteFlatFile para consultarlo con
+// pCmp, que debe ser el mismo comparador del árbol original.
+func OpenFlatFile(pPath string, pCmp Cmp) (*FlatTree, error) {
+      data, release, err := mapFile(pPath)
+      if err != nil {
+            return nil, err
+      }
+      flat, err := newFlatTree(data, pCmp)
+      if err != nil {
+            release()
+            return nil, fmt.Errorf("%s: %v", pPath, err)
+      }
+      flat.release = release
+      return flat, nil
+}
+
+// newFlatTree revisa el formato de data y ubica la tabla de desplazamientos.
+func newFlatTree(data []byte, pCmp Cmp) (*FlatTree, error) {
+      size := uint64(len(data))
+      if size < uint64(len(flatMagic)+flatFooterSize) ||
+            string(data[:len(flatMagic)]) != flatMagic ||
+            string(data[size-uint64(len(flatMagic)):]) != flatMagic {
+            return nil, fmt.Errorf("no es un árbol plano")
+      }
+
+      footer := data[size-uint64(flatFooterSize):]
+      count := binary.BigEndian.Uint64(footer)
+      start := binary.BigEndian.Uint64(footer[8:])
+      tableEnd := size - uint64(flatFooterSize)
+      // La tabla tiene count+1 entradas; se compara con count sin sumarle 1
+      // para que una cantidad enorme no dé la vuelta.
+      if start > tableEnd || (tableEnd-start)%8 != 0 ||
+            tableEnd == start || count != (tableEnd-start)/8-1 {
+            return nil, fmt.Errorf("tabla de desplazamientos inválida")
+      }
+
+      flat := &FlatTree{data: data, offsets: data[start:tableEnd], count: int(count), cmp: pCmp}
+      // Los desplazamientos deben ser crecientes, el último debe ser el fin de
+      // los datos y cada valor debe ocupar exactamente su rango.
+      prev := uint64(len(flatMagic))
+      for i := 0; i <= flat.count; i++ {
+            o := flat.offset(i)
+            if o < prev || o > start || (i == flat.count && o != start) {
+                  return nil, fmt.Errorf("desplazamiento %d inválido", i)
+            }
+            if i > 0 {
+                  if _, n, err := decodeValue(data[prev:o], true); err != nil || uint64(n) != o-prev {
+                        return nil, fmt.Errorf("valor %d inválido", i-1)
+                  }
+            }
+            prev = o
+      }
+      return flat, nil
+}
+
+func (flat *FlatTree) offset(i int) uint64 {
+      return binary.BigEndian.Uint64(flat.offsets[8*i:])
+}
+
+// Len devuelve la cantidad de valores.
+func (flat *FlatTree) Len() int {
+      return flat.count
+}
+
+// At devuelve el i-ésimo valor en orden (desde 0).
+func (flat *FlatTree) At(i int) interface{} {
+      if i < 0 || i >= flat.count {
+            panic(fmt.Sprintf("Índice %d fuera del árbol plano de %d valores", i, flat.count))
+      }
+      // newFlatTree ya revisó que el valor se puede leer.
+      value, _, _ := decodeValue(flat.data[flat.offset(i):flat.offset(i+1)], true)
+      return value
+}
+
+// search devuelve la posición del primer valor mayor o igual a pKey.
+func (flat *FlatTree) search(pKey interface{}) int {
+      return sort.Search(flat.count, func(i int) bool {
+            return flat.cmp(flat.At(i), pKey) >= 0
+      })
+}
+
+// Find indica si pKey está en el árbol y devuelve el valor guardado.
+func (flat *FlatTree) Find(pKey interface{}) (bool, interface{}) {
+      i := flat.search(pKey)
+      if i < flat.count {
+            if value := flat.At(i); flat.cmp(value, pKey) == 0 {
+                  return true, value
+            }
+      }
+      return false, nil
+}
+
+// Floor devuelve el mayor valor menor o igual a pKey, y false si no hay.
+func (flat *FlatTree) Floor(pKey interface{}) (interface{}, bool) {
+      i := flat.search(pKey)
+      if i < flat.count {
+            if value := flat.At(i); flat.cmp(value, pKey) == 0 {
+                  return value, true
+            }
+      }
+      if i == 0 {
+            return nil, false
+      }
+      return flat.At(i - 1), true
+}
+
+// Ceiling devuelve el menor valor mayor o igual a pKey, y false si no hay.
+func (flat *FlatTree) Ceiling(pKey interface{}) (interface{}, bool) {
+      i := flat.search(pKey)
+      if i == flat.count {
+            return nil, false
+      }
+      return flat.At(i), true
+}
+
+// Range aplica fn, en orden, a los valores en el intervalo cerrado
+// [pLow, pHigh], hasta que fn devuelva false.
+func (flat *FlatTree) Range(pLow, pHigh interface{}, fn func(interface{}) bool) {
+      for i := flat.search(pLow); i < flat.count; i++ {
+            value := flat.At(i)
+            if flat.cmp(value, pHigh) > 0 || !fn(value) {
+                  return
+            }
+      }
+}
+
+// Close libera el archivo. Los valores obtenidos antes dejan de ser válidos.
+func (flat *FlatTree) Close() error {
+      flat.data, flat.offsets, flat.count = nil, nil, 0
+      if flat.release == nil {
+            return nil
+      }
+      release := flat.release
+      flat.release = nil
+      return release()
+}
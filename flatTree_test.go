@@ -0,0 +1,172 @@
+package redBlackTree
+
+import (
+      "encoding/binary"
+      "math"
+      "os"
+      "path/filepath"
+      "reflect"
+      "testing"
+)
+
+func writeFlatValues(t *testing.T, pPath string, pValues ...interface{}) {
+      t.Helper()
+      tree := NewTree(StringCmp)
+      for _, v := range pValues {
+            tree.Insert(v)
+      }
+      if err := WriteFlatFile(pPath, tree); err != nil {
+            t.Fatal(err)
+      }
+}
+
+func TestFlatTreeQueries(t *testing.T) {
+      path := filepath.Join(t.TempDir(), "flat")
+      tree := NewTree(IntCmp)
+      for v := 0; v < 100; v += 2 {
+            tree.Insert(v)
+      }
+      if err := WriteFlatFile(path, tree); err != nil {
+            t.Fatal(err)
+      }
+      flat, err := OpenFlatFile(path, IntCmp)
+      if err != nil {
+            t.Fatal(err)
+      }
+      defer flat.Close()
+
+      if flat.Len() != 50 || flat.At(0) != 0 || flat.At(49) != 98 {
+            t.Fatalf("Len = %d, At(0) = %v, At(49) = %v", flat.Len(), flat.At(0), flat.At(49))
+      }
+      for v := -1; v <= 100; v++ {
+            found, _ := flat.Find(v)
+            if found != tree.FindKey(v) {
+                  t.Fatalf("Find(%d) = %v", v, found)
+            }
+      }
+      if v, ok := flat.Floor(7); !ok || v != 6 {
+            t.Fatalf("Floor(7) = %v, %v", v, ok)
+      }
+      if v, ok := flat.Ceiling(7); !ok || v != 8 {
+            t.Fatalf("Ceiling(7) = %v, %v", v, ok)
+      }
+      if _, ok := flat.Floor(-1); ok {
+            t.Fatal("Floor(-1) encontró un valor")
+      }
+      if _, ok := flat.Ceiling(99); ok {
+            t.Fatal("Ceiling(99) encontró un valor")
+      }
+      got := []interface{}{}
+      flat.Range(11, 20, func(v interface{}) bool {
+            got = append(got, v)
+            return true
+      })
+      if want := tree.RangeValues(11, 20); !reflect.DeepEqual(got, want) {
+            t.Fatalf("Range = %v, se esperaba %v", got, want)
+      }
+}
+
+func TestFlatTreeEmpty(t *testing.T) {
+      path := filepath.Join(t.TempDir(), "flat")
+      writeFlatValues(t, path)
+      flat, err := OpenFlatFile(path, StringCmp)
+      if err != nil {
+            t.Fatal(err)
+      }
+      defer flat.Close()
+      if found, _ := flat.Find("a"); flat.Len() != 0 || found {
+            t.Fatalf("Len = %d, Find = %v", flat.Len(), found)
+      }
+}
+
+// Un archivo dañado se rechaza al abrirlo, en vez de fallar en una consulta.
+func TestFlatTreeRejectsCorruptFiles(t *testing.T) {
+      path := filepath.Join(t.TempDir(), "flat")
+      writeFlatValues(t, path, "alfa", "beta", "gamma")
+      good, err := os.ReadFile(path)
+      if err != nil {
+            t.Fatal(err)
+      }
+      footer := len(good) - flatFooterSize
+
+      // Archivo vacío con una cantidad que da la vuelta al sumarle 1.
+      overflow := []byte(flatMagic)
+      overflow = binary.BigEndian.AppendUint64(overflow, math.MaxUint64)
+      overflow = binary.BigEndian.AppendUint64(overflow, uint64(len(flatMagic)))
+      overflow = append(overflow, flatMagic...)
+
+      tests := []struct {
+            name   string
+            change func([]byte) []byte
+      }{
+            {"etiqueta de valor desconocida", func(b []byte) []byte {
+                  b[len(flatMagic)] = 0xff
+                  return b
+            }},
+            {"largo de hilera mayor que el valor", func(b []byte) []byte {
+                  b[len(flatMagic)+1] = 0x7f
+                  return b
+            }},
+            {"cantidad que da la vuelta", func([]byte) []byte {
+                  return overflow
+            }},
+            {"cantidad mayor que la tabla", func(b []byte) []byte {
+                  binary.BigEndian.PutUint64(b[footer:], 4)
+                  return b
+            }},
+            {"último desplazamiento antes del fin de los datos", func(b []byte) []byte {
+                  start := binary.BigEndian.Uint64(b[footer+8:])
+                  binary.BigEndian.PutUint64(b[footer-8:], start-1)
+                  return b
+            }},
+            {"archivo cortado", func(b []byte) []byte {
+                  return b[:len(b)-1]
+            }},
+      }
+      for _, test := range tests {
+            t.Run(test.name, func(t *testing.T) {
+                  bad := test.change(append([]byte{}, good...))
+                  if err := os.WriteFile(path, bad, 0o644); err != nil {
+                        t.Fatal(err)
+                  }
+                  if flat, err := OpenFlatFile(path, StringCmp); err == nil {
+                        flat.Close()
+                        t.Fatal("se abrió un archivo dañado")
+                  }
+            })
+      }
+}
+
+// Si WriteFlatFile falla, el archivo anterior queda intacto y no quedan
+// archivos temporales.
+func TestWriteFlatFileKeepsOldFile(t *testing.T) {
+      dir := t.TempDir()
+      path := filepath.Join(dir, "flat")
+      writeFlatValues(t, path, "alfa", "beta")
+
+      // int32 no se puede codificar.
+      tree := NewTree(func(o1, o2 interface{}) int {
+            return int(o1.(int32) - o2.(int32))
+      })
+      tree.Insert(int32(1))
+      tree.Insert(int32(2))
+      if err := WriteFlatFile(path, tree); err == nil {
+            t.Fatal("WriteFlatFile escribió un valor que no se puede codificar")
+      }
+
+      entries, err := os.ReadDir(dir)
+      if err != nil {
+            t.Fatal(err)
+      }
+      if len(entries) != 1 {
+            t.Fatalf("el directorio tiene %d archivos", len(entries))
+      }
+      flat, err := OpenFlatFile(path, StringCmp)
+      if err != nil {
+            t.Fatal(err)
+      }
+      defer flat.Close()
+      if flat.Len() != 2 || flat.At(1) != "beta" {
+            t.Fatalf("el archivo anterior cambió: Len = %d", flat.Len())
+      }
+}
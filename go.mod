@@ -0,0 +1,3 @@
+module github.com/luahir/Tarea-1-LP
+
+go 1.22
@@ -0,0 +1,184 @@
+/* Árbol de intervalos construido sobre el árbol rojinegro.
+   Cada nodo guarda, además del intervalo, el extremo superior más grande de
+   su subárbol, lo que permite descartar ramas completas al buscar traslapes.
+*/
+
+package redBlackTree
+
+import (
+      "fmt"
+)
+
+// Intervalo cerrado [Low, High].
+type Interval struct {
+      Low, High int
+}
+
+// Función para desplegar el intervalo mediante print.
+func (pInterval Interval) String() string {
+      return fmt.Sprintf("[%d, %d]", pInterval.Low, pInterval.High)
+}
+
+// Overlaps indica si el intervalo tiene algún punto en común con [pLow, pHigh].
+func (pInterval Interval) Overlaps(pLow, pHigh int) bool {
+      return pInterval.Low <= pHigh && pLow <= pInterval.High
+}
+
+// Los intervalos se ordenan por su inicio y, si comparten el inicio, por su
+// final, de modo que varios intervalos pueden empezar en el mismo punto.
+func IntervalCmp(o1, o2 interface{}) int {
+      i1, i2 := o1.(Interval), o2.(Interval)
+
+      switch {
+      case i1.Low > i2.Low:
+            return 1
+      case i1.Low < i2.Low:
+            return -1
+      case i1.High > i2.High:
+            return 1
+      case i1.High < i2.High:
+            return -1
+      default:
+            return 0
+      }
+}
+
+// El árbol de intervalos es un árbol rojinegro ordenado con IntervalCmp, cuyo
+// campo aug guarda en cada nodo el extremo superior máximo de su subárbol.
+type IntervalTree struct {
+      tree *RBTree
+}
+
+// Se define un nuevo árbol de intervalos vacío.
+func NewIntervalTree() *IntervalTree {
+      tree := NewTree(IntervalCmp)
+      tree.update = updateMaxEnd
+      return &IntervalTree{tree: tree}
+}
+
+// updateMaxEnd calcula el extremo máximo del subárbol de node a partir del de
+// sus hijos, que ya deben estar actualizados.
+func updateMaxEnd(node *Node) {
+      max := node.value.(Interval).High
+      for _, child := range []*Node{node.left, node.right} {
+            if child != nil && child.aug.(int) > max {
+                  max = child.aug.(int)
+            }
+      }
+      node.aug = max
+}
+
+// Insert agrega el intervalo y devuelve true, o false si ya estaba en el árbol
+// o si es vacío (Low > High).
+func (itree *IntervalTree) Insert(pInterval Interval) bool {
+      if pInterval.Low > pInterval.High {
+            return false
+      }
+      return itree.tree.Insert(pInterval)
+}
+
+// Delete elimina el intervalo y devuelve true, o false si no estaba en el árbol.
+func (itree *IntervalTree) Delete(pInterval Interval) bool {
+      if itree.tree.lookup(pInterval) == nil {
+            return false
+      }
+      itree.tree.Delete(pInterval)
+      return true
+}
+
+// Overlapping devuelve, ordenados, los intervalos que contienen el punto pPoint.
+func (itree *IntervalTree) Overlapping(pPoint int) []Interval {
+      return itree.OverlappingRange(pPoint, pPoint)
+}
+
+// OverlappingRange devuelve, ordenados, los intervalos que se traslapan con
+// [pLow, pHigh]. Se descartan los subárboles cuyo extremo máximo es menor que
+// pLow y, como el árbol está ordenado por inicio, los que empiezan después de
+// pHigh.
+func (itree *IntervalTree) OverlappingRange(pLow, pHigh int) []Interval {
+      intervals := []Interval{}
+
+      var visit func(*Node)
+      visit = func(node *Node) {
+            if node == nil || node.aug.(int) < pLow {
+                  return
+            }
+            visit(node.left)
+
+            interval := node.value.(Interval)
+            if interval.Low > pHigh {
+                  return
+            }
+            if interval.Overlaps(pLow, pHigh) {
+                  intervals = append(intervals, interval)
+            }
+            visit(node.right)
+      }
+      visit(itree.tree.root)
+
+      return intervals
+}
+
+// AnyOverlap devuelve algún intervalo que se traslape con [pLow, pHigh] y true,
+// o false si no hay ninguno. Solamente recorre un camino desde la raíz.
+func (itree *IntervalTree) AnyOverlap(pLow, pHigh int) (Interval, bool) {
+      node := itree.tree.root
+      for node != nil {
+            interval := node.value.(Interval)
+            if interval.Overlaps(pLow, pHigh) {
+                  return interval, true
+            }
+            // Si el subárbol izquierdo llega hasta pLow y no tiene traslapes,
+            // tampoco puede haberlos a la derecha, pues todos esos intervalos
+            // empiezan después de pHigh.
+            if node.left != nil && node.left.aug.(int) >= pLow {
+                  node = node.left
+            } else {
+                  node = node.right
+            }
+      }
+      return Interval{}, false
+}
+
+// Len devuelve la cantidad de intervalos en el árbol.
+func (itree *IntervalTree) Len() int {
+      return itree.tree.count
+}
+
+// Validate revisa las condiciones del árbol rojinegro y que el extremo máximo
+// guardado en cada nodo sea el de su subárbol.
+func (itree *IntervalTree) Validate() error {
+      if err := itree.tree.Validate(); err != nil {
+            return err
+      }
+      var check func(*Node) (int, error)
+      check = func(node *Node) (int, error) {
+            max := node.value.(Interval).High
+            for _, child := range []*Node{node.left, node.right} {
+                  if child == nil {
+                        continue
+                  }
+                  childMax, err := check(child)
+                  if err != nil {
+                        return 0, err
+                  }
+                  if childMax > max {
+                        max = childMax
+                  }
+            }
+            if node.aug != max {
+                  return 0, fmt.Errorf("el extremo máximo de %v es %v y debería ser %d", node, node.aug, max)
+            }
+            return max, nil
+      }
+      if itree.tree.root == nil {
+            return nil
+      }
+      _, err := check(itree.tree.root)
+      return err
+}
+
+// Función para desplegar los intervalos en orden mediante print.
+func (itree *IntervalTree) String() string {
+      return itree.tree.String()
+}
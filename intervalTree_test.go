@@ -0,0 +1,96 @@
+package redBlackTree
+
+import (
+      "math/rand"
+      "reflect"
+      "sort"
+      "testing"
+)
+
+// overlapping busca los traslapes recorriendo todos los intervalos.
+func overlapping(pIntervals map[Interval]bool, pLow, pHigh int) []Interval {
+      found := []Interval{}
+      for interval := range pIntervals {
+            if interval.Overlaps(pLow, pHigh) {
+                  found = append(found, interval)
+            }
+      }
+      sort.Slice(found, func(i, j int) bool {
+            return IntervalCmp(found[i], found[j]) < 0
+      })
+      return found
+}
+
+// Compara las consultas del árbol con una búsqueda exhaustiva después de cada
+// inserción y borrado, y revisa el extremo máximo de cada nodo.
+func TestIntervalTreeMatchesBruteForce(t *testing.T) {
+      r := rand.New(rand.NewSource(1))
+      itree := NewIntervalTree()
+      intervals := map[Interval]bool{}
+
+      for step := 0; step < 2000; step++ {
+            // Los inicios se eligen de un rango pequeño para que se repitan.
+            low := r.Intn(50)
+            interval := Interval{low, low + r.Intn(20)}
+            if r.Intn(3) == 0 {
+                  if itree.Delete(interval) != intervals[interval] {
+                        t.Fatalf("paso %d: Delete(%v) no coincide", step, interval)
+                  }
+                  delete(intervals, interval)
+            } else {
+                  if itree.Insert(interval) == intervals[interval] {
+                        t.Fatalf("paso %d: Insert(%v) no coincide", step, interval)
+                  }
+                  intervals[interval] = true
+            }
+            if err := itree.Validate(); err != nil {
+                  t.Fatalf("paso %d: %v", step, err)
+            }
+            if itree.Len() != len(intervals) {
+                  t.Fatalf("paso %d: Len() = %d, se esperaba %d", step, itree.Len(), len(intervals))
+            }
+
+            qLow := r.Intn(80) - 5
+            qHigh := qLow + r.Intn(10)
+            want := overlapping(intervals, qLow, qHigh)
+            if got := itree.OverlappingRange(qLow, qHigh); !reflect.DeepEqual(got, want) {
+                  t.Fatalf("paso %d: OverlappingRange(%d, %d) = %v, se esperaba %v", step, qLow, qHigh, got, want)
+            }
+            if got, want := itree.Overlapping(qLow), overlapping(intervals, qLow, qLow); !reflect.DeepEqual(got, want) {
+                  t.Fatalf("paso %d: Overlapping(%d) = %v, se esperaba %v", step, qLow, got, want)
+            }
+            hit, found := itree.AnyOverlap(qLow, qHigh)
+            if found != (len(want) > 0) || (found && !intervals[hit]) || (found && !hit.Overlaps(qLow, qHigh)) {
+                  t.Fatalf("paso %d: AnyOverlap(%d, %d) = %v, %v", step, qLow, qHigh, hit, found)
+            }
+      }
+}
+
+func TestIntervalTreeSharedStarts(t *testing.T) {
+      itree := NewIntervalTree()
+      for _, interval := range []Interval{{1, 5}, {1, 2}, {1, 9}, {3, 4}} {
+            if !itree.Insert(interval) {
+                  t.Fatalf("Insert(%v) = false", interval)
+            }
+      }
+      want := []Interval{{1, 5}, {1, 9}, {3, 4}}
+      if got := itree.OverlappingRange(3, 3); !reflect.DeepEqual(got, want) {
+            t.Fatalf("OverlappingRange(3, 3) = %v, se esperaba %v", got, want)
+      }
+      if got := itree.Overlapping(1); len(got) != 3 || got[0] != (Interval{1, 2}) {
+            t.Fatalf("Overlapping(1) = %v", got)
+      }
+}
+
+func TestIntervalTreeRejects(t *testing.T) {
+      itree := NewIntervalTree()
+      if itree.Insert(Interval{5, 1}) {
+            t.Fatal("se insertó un intervalo vacío")
+      }
+      if itree.Delete(Interval{1, 2}) {
+            t.Fatal("Delete de un intervalo ausente devolvió true")
+      }
+      if _, found := itree.AnyOverlap(0, 10); found || itree.Len() != 0 {
+            t.Fatal("el árbol vacío tiene traslapes")
+      }
+}
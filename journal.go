@@ -0,0 +1,215 @@
+/* Bitácora para deshacer y rehacer cambios en el árbol.
+   Si se activa con SetJournal, cada Insert, Delete, Replace y Clear guarda lo
+   necesario para revertirlo: el valor insertado, el valor que se borró (que
+   puede ser distinto de la llave usada en Delete), el que se reemplazó o el
+   contenido completo que se borró con Clear. Undo revierte el último cambio
+   y Redo lo vuelve a aplicar; un cambio nuevo descarta lo que se podía
+   rehacer.
+
+   La bitácora guarda a lo sumo la cantidad de cambios indicada; al pasarse
+   se olvidan los más antiguos. Los puntos de control (Checkpoint) marcan una
+   posición de la bitácora a la que se puede volver con UndoTo.
+*/
+
+package redBlackTree
+
+import (
+      "fmt"
+)
+
+// Tipos de entrada de la bitácora.
+const (
+      journalInsert byte = iota + 1
+      journalDelete
+      journalClear
+      journalReplace
+      journalCheckpoint
+)
+
+// Entrada de la bitácora. values tiene el valor insertado o borrado, el
+// anterior y el nuevo de Replace, o el contenido borrado por Clear, y name el
+// nombre de un punto de control.
+type journalEntry struct {
+      op     byte
+      values []interface{}
+      name   string
+}
+
+// La bitácora guarda las entradas en orden; las anteriores a pos pueden
+// deshacerse y las siguientes rehacerse. changes cuenta los cambios (sin los
+// puntos de control) antes de pos, para respetar depth.
+type journal struct {
+      entries   []journalEntry
+      pos       int
+      changes   int
+      depth     int
+      replaying bool
+}
+
+// SetJournal activa la bitácora del árbol, guardando a lo sumo pDepth
+// cambios. Con pDepth menor o igual a 0 se desactiva. En ambos casos se
+// descarta la historia anterior.
+func (tree *RBTree) SetJournal(pDepth int) {
+      if pDepth <= 0 {
+            tree.journal = nil
+            return
+      }
+      tree.journal = &journal{depth: pDepth}
+}
+
+// record agrega una entrada a la bitácora, si está activa y no se está
+// deshaciendo o rehaciendo un cambio. Quien llama revisa antes que tree.journal
+// no sea nil, pues armar los argumentos de record reserva memoria.
+func (tree *RBTree) record(op byte, values ...interface{}) {
+      j := tree.journal
+      if j == nil || j.replaying {
+            return
+      }
+      j.entries = append(j.entries[:j.pos], journalEntry{op: op, values: values})
+      j.pos++
+      j.changes++
+
+      // Se olvidan los cambios más antiguos, junto con los puntos de control
+      // que los preceden.
+      drop := 0
+      for j.changes > j.depth {
+            if j.entries[drop].op != journalCheckpoint {
+                  j.changes--
+            }
+            drop++
+      }
+      if drop > 0 {
+            j.entries = append(j.entries[:0], j.entries[drop:]...)
+            j.pos -= drop
+      }
+}
+
+// Checkpoint marca la posición actual de la bitácora con pName. Entra en
+// pánico si la bitácora no está activa.
+func (tree *RBTree) Checkpoint(pName string) {
+      j := tree.journal
+      if j == nil {
+            panic("El árbol no tiene bitácora")
+      }
+      j.entries = append(j.entries[:j.pos], journalEntry{op: journalCheckpoint, name: pName})
+      j.pos++
+}
+
+// CanUndo indica si hay un cambio que se pueda deshacer.
+func (tree *RBTree) CanUndo() bool {
+      return tree.journal != nil && tree.journal.changes > 0
+}
+
+// CanRedo indica si hay un cambio que se pueda rehacer.
+func (tree *RBTree) CanRedo() bool {
+      j := tree.journal
+      if j == nil {
+            return false
+      }
+      for _, entry := range j.entries[j.pos:] {
+            if entry.op != journalCheckpoint {
+                  return true
+            }
+      }
+      return false
+}
+
+// Undo revierte el último cambio y devuelve false si no había ninguno. Los
+// puntos de control que estén en medio se saltan.
+func (tree *RBTree) Undo() bool {
+      if !tree.CanUndo() {
+            return false
+      }
+      j := tree.journal
+      for {
+            j.pos--
+            entry := j.entries[j.pos]
+            if entry.op != journalCheckpoint {
+                  j.changes--
+                  tree.replay(entry, true)
+                  return true
+            }
+      }
+}
+
+// Redo vuelve a aplicar el último cambio deshecho y devuelve false si no
+// había ninguno.
+func (tree *RBTree) Redo() bool {
+      if !tree.CanRedo() {
+            return false
+      }
+      j := tree.journal
+      for {
+            entry := j.entries[j.pos]
+            j.pos++
+            if entry.op != journalCheckpoint {
+                  j.changes++
+                  tree.replay(entry, false)
+                  return true
+            }
+      }
+}
+
+// UndoTo deshace los cambios posteriores al último punto de control llamado
+// pName, que luego pueden rehacerse con Redo. Devuelve un error, sin cambiar
+// nada, si ese punto de control no está en la parte de la bitácora que se
+// puede deshacer.
+func (tree *RBTree) UndoTo(pName string) error {
+      j := tree.journal
+      target := -1
+      if j != nil {
+            for i := j.pos - 1; i >= 0; i-- {
+                  if j.entries[i].op == journalCheckpoint && j.entries[i].name == pName {
+                        target = i + 1
+                        break
+                  }
+            }
+      }
+      if target < 0 {
+            return fmt.Errorf("no existe el punto de control %q", pName)
+      }
+      // Los puntos de control intermedios se saltan aquí, pues Undo podría
+      // pasar de largo el buscado.
+      for j.pos > target {
+            if j.entries[j.pos-1].op == journalCheckpoint {
+                  j.pos--
+            } else {
+                  tree.Undo()
+            }
+      }
+      return nil
+}
+
+// replay aplica la entrada, o su inversa si pInverse es true, sin registrarla
+// de nuevo en la bitácora.
+func (tree *RBTree) replay(entry journalEntry, pInverse bool) {
+      tree.journal.replaying = true
+      defer func() { tree.journal.replaying = false }()
+
+      switch {
+      case entry.op == journalInsert && !pInverse, entry.op == journalDelete && pInverse:
+            tree.Insert(entry.values[0])
+      case entry.op == journalDelete && !pInverse, entry.op == journalInsert && pInverse:
+            tree.Delete(entry.values[0])
+      case entry.op == journalClear && !pInverse:
+            tree.Clear()
+      case entry.op == journalReplace && !pInverse:
+            tree.Replace(entry.values[1])
+      case entry.op == journalReplace && pInverse:
+            tree.Replace(entry.values[0])
+      case entry.op == journalClear && pInverse:
+            for _, value := range entry.values {
+                  tree.Insert(value)
+            }
+      }
+}
+
+// contents devuelve los valores del árbol en in-order.
+func (tree *RBTree) contents() []interface{} {
+      values := make([]interface{}, 0, tree.count)
+      iter := &InorderIterator{}
+      for node := range iter.Iterate(tree.root) {
+            values = append(values, node.value)
+      }
+      return values
+}
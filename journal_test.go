@@ -0,0 +1,159 @@
+package redBlackTree
+
+import (
+      "reflect"
+      "testing"
+)
+
+// Cambios de prueba sobre un árbol de entradas. Delete usa una llave sin
+// dato, así que deshacerlo debe reinsertar el valor guardado, no la llave.
+var journalOps = []struct {
+      name string
+      op   func(*RBTree)
+}{
+      {"Insert 1", func(tree *RBTree) { tree.Insert(entry{1, "uno"}) }},
+      {"Insert 2", func(tree *RBTree) { tree.Insert(entry{2, "dos"}) }},
+      {"Insert 3", func(tree *RBTree) { tree.Insert(entry{3, "tres"}) }},
+      {"Replace 2", func(tree *RBTree) { tree.Replace(entry{2, "DOS"}) }},
+      {"Delete 1", func(tree *RBTree) { tree.Delete(entry{key: 1}) }},
+      {"Clear", func(tree *RBTree) { tree.Clear() }},
+      {"Insert 4", func(tree *RBTree) { tree.Insert(entry{4, "cuatro"}) }},
+}
+
+// applyJournalOps aplica journalOps y devuelve el contenido del árbol antes
+// de empezar y después de cada cambio.
+func applyJournalOps(tree *RBTree) [][]interface{} {
+      states := [][]interface{}{tree.contents()}
+      for _, op := range journalOps {
+            op.op(tree)
+            states = append(states, tree.contents())
+      }
+      return states
+}
+
+func checkContents(t *testing.T, pStep string, tree *RBTree, pWant []interface{}) {
+      t.Helper()
+      if err := tree.Validate(); err != nil {
+            t.Fatalf("%s: %v", pStep, err)
+      }
+      if got := tree.contents(); !reflect.DeepEqual(got, pWant) {
+            t.Fatalf("%s: el árbol tiene %v, se esperaba %v", pStep, got, pWant)
+      }
+}
+
+// Undo recorre los estados hacia atrás y Redo hacia adelante.
+func TestJournalUndoRedo(t *testing.T) {
+      tree := NewTree(entryCmp)
+      tree.SetJournal(100)
+      states := applyJournalOps(tree)
+
+      for i := len(journalOps) - 1; i >= 0; i-- {
+            if !tree.Undo() {
+                  t.Fatalf("Undo de %s devolvió false", journalOps[i].name)
+            }
+            checkContents(t, "Undo de "+journalOps[i].name, tree, states[i])
+      }
+      if tree.CanUndo() || tree.Undo() {
+            t.Fatal("se pudo deshacer más allá del inicio")
+      }
+
+      for i, op := range journalOps {
+            if !tree.Redo() {
+                  t.Fatalf("Redo de %s devolvió false", op.name)
+            }
+            checkContents(t, "Redo de "+op.name, tree, states[i+1])
+      }
+      if tree.CanRedo() || tree.Redo() {
+            t.Fatal("se pudo rehacer más allá del final")
+      }
+}
+
+// Un cambio nuevo después de Undo descarta lo que se podía rehacer.
+func TestJournalNewWriteDropsRedo(t *testing.T) {
+      tree := NewTree(IntCmp)
+      tree.SetJournal(10)
+      tree.Insert(1)
+      tree.Insert(2)
+      tree.Undo()
+      if !tree.CanRedo() {
+            t.Fatal("CanRedo() = false después de Undo")
+      }
+      tree.Insert(3)
+      if tree.CanRedo() || tree.Redo() {
+            t.Fatal("se pudo rehacer después de un cambio nuevo")
+      }
+      checkContents(t, "Insert 3", tree, []interface{}{1, 3})
+      tree.Undo()
+      tree.Undo()
+      checkContents(t, "Undo", tree, []interface{}{})
+
+      // Un cambio que no modifica el árbol no se registra ni descarta nada.
+      tree.Redo()
+      tree.Insert(1)
+      tree.Delete(5)
+      if !tree.CanRedo() {
+            t.Fatal("un cambio sin efecto descartó lo que se podía rehacer")
+      }
+}
+
+func TestJournalUndoTo(t *testing.T) {
+      tree := NewTree(IntCmp)
+      tree.SetJournal(10)
+      tree.Insert(1)
+      tree.Checkpoint("a")
+      tree.Insert(2)
+      tree.Checkpoint("b")
+      tree.Insert(3)
+      tree.Checkpoint("a")
+      tree.Insert(4)
+
+      // Se vuelve al último punto de control con ese nombre.
+      if err := tree.UndoTo("a"); err != nil {
+            t.Fatal(err)
+      }
+      checkContents(t, "UndoTo(a)", tree, []interface{}{1, 2, 3})
+      if err := tree.UndoTo("a"); err != nil {
+            t.Fatal(err)
+      }
+      checkContents(t, "UndoTo(a) otra vez", tree, []interface{}{1, 2, 3})
+      if err := tree.UndoTo("b"); err != nil {
+            t.Fatal(err)
+      }
+      checkContents(t, "UndoTo(b)", tree, []interface{}{1, 2})
+
+      // Los puntos de control después de la posición actual no cuentan.
+      if err := tree.UndoTo("c"); err == nil {
+            t.Fatal("UndoTo de un punto de control inexistente no devolvió un error")
+      }
+      tree.Redo()
+      tree.Redo()
+      checkContents(t, "Redo", tree, []interface{}{1, 2, 3, 4})
+}
+
+// Con profundidad 3 solamente se deshacen los 3 últimos cambios, y se olvidan
+// los puntos de control anteriores a ellos.
+func TestJournalDepth(t *testing.T) {
+      tree := NewTree(IntCmp)
+      tree.SetJournal(3)
+      tree.Checkpoint("inicio")
+      for i := 1; i <= 5; i++ {
+            tree.Insert(i)
+      }
+      undone := 0
+      for tree.Undo() {
+            undone++
+      }
+      if undone != 3 {
+            t.Fatalf("se deshicieron %d cambios, se esperaban 3", undone)
+      }
+      checkContents(t, "Undo", tree, []interface{}{1, 2})
+      if err := tree.UndoTo("inicio"); err == nil {
+            t.Fatal("no se olvidó el punto de control anterior a los cambios")
+      }
+
+      // Sin bitácora no hay nada que deshacer.
+      tree.SetJournal(0)
+      if tree.CanUndo() || tree.Undo() || tree.CanRedo() {
+            t.Fatal("el árbol sin bitácora puede deshacer")
+      }
+}
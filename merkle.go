@@ -0,0 +1,195 @@
+/* Resúmenes (hashes) del contenido de un árbol.
+   La forma de un árbol rojinegro depende del orden de inserción, así que el
+   resumen no puede combinar los hashes según la forma, como en un árbol de
+   Merkle común. En su lugar se usa un hash polinomial de la secuencia
+   ordenada de valores:
+      H(v1, ..., vn) = h(v1)·B^(n-1) + h(v2)·B^(n-2) + ... + h(vn)  (mod 2^61 - 1)
+   que puede calcularse juntando los resúmenes de dos partes contiguas, pues
+   H(x ++ y) = H(x)·B^|y| + H(y). Por eso se mantiene como el agregado de un
+   árbol aumentado: cada nodo guarda el resumen de su subárbol, dos árboles
+   con los mismos valores tienen el mismo resumen sin importar su forma y el
+   resumen de cualquier rango de llaves se obtiene en O(log n).
+
+   El resumen no es criptográfico: sirve para detectar diferencias entre
+   réplicas, no para protegerse de quien escoja los valores a propósito.
+*/
+
+package redBlackTree
+
+import (
+      "math/bits"
+)
+
+// Un Hasher resume un valor del árbol. Dos valores que deben considerarse
+// iguales entre réplicas deben tener el mismo hash.
+type Hasher func(interface{}) uint64
+
+const (
+      // Módulo del hash polinomial, el primo de Mersenne 2^61 - 1.
+      hashModulus = 1<<61 - 1
+      // Base del hash polinomial.
+      hashBase = 0x1d8e4e27c47d124f % hashModulus
+)
+
+// Resumen de una secuencia de valores: el hash y B elevado al largo.
+type digest struct {
+      hash, pow uint64
+}
+
+// Resumen de la secuencia vacía.
+var emptyDigest = digest{hash: 0, pow: 1}
+
+// mulMod multiplica módulo 2^61 - 1 sin desbordarse.
+func mulMod(a, b uint64) uint64 {
+      hi, lo := bits.Mul64(a, b)
+      // a·b = hi·2^64 + lo y 2^61 ≡ 1, así que se suman los grupos de 61 bits.
+      n := (hi<<3 | lo>>61) + lo&hashModulus
+      if n >= hashModulus {
+            n -= hashModulus
+      }
+      return n
+}
+
+// concat devuelve el resumen de la secuencia x seguida de y.
+func (x digest) concat(y digest) digest {
+      hash := mulMod(x.hash, y.pow) + y.hash
+      if hash >= hashModulus {
+            hash -= hashModulus
+      }
+      return digest{hash: hash, pow: mulMod(x.pow, y.pow)}
+}
+
+// Monoide que mantiene el resumen de cada subárbol.
+type hashMonoid struct {
+      hasher Hasher
+}
+
+func (hashMonoid) Identity() interface{} {
+      return emptyDigest
+}
+
+func (m hashMonoid) Combine(left, self, right interface{}) interface{} {
+      return left.(digest).concat(m.single(self)).concat(right.(digest))
+}
+
+// single devuelve el resumen de la secuencia que solamente tiene pValue. Se
+// reserva el 0 para que un valor no se confunda con la secuencia vacía.
+func (m hashMonoid) single(pValue interface{}) digest {
+      return digest{hash: m.hasher(pValue)%(hashModulus-1) + 1, pow: hashBase}
+}
+
+// Se define un nuevo árbol que mantiene el resumen de su contenido con los
+// hashes de pHasher.
+func NewMerkleTree(pCmp Cmp, pHasher Hasher) *RBTree {
+      return NewAugmentedTree(pCmp, NewHashMonoid(pHasher))
+}
+
+// NewHashMonoid devuelve el monoide de los árboles de NewMerkleTree, para
+// juntarlo con otros en Monoids.
+func NewHashMonoid(pHasher Hasher) Monoid {
+      return hashMonoid{hasher: pHasher}
+}
+
+// El monoide de resúmenes de un árbol y su posición en el monoide del árbol
+// (ver findMonoid).
+type hashPart struct {
+      hashMonoid
+      part int
+}
+
+// hashPart busca el monoide de resúmenes del árbol y entra en pánico si el
+// árbol no mantiene resúmenes.
+func (tree *RBTree) hashPart() hashPart {
+      m, i, ok := tree.findMonoid(func(m Monoid) bool {
+            _, ok := m.(hashMonoid)
+            return ok
+      })
+      if !ok {
+            panic("El árbol no mantiene resúmenes")
+      }
+      return hashPart{m.(hashMonoid), i}
+}
+
+// RootHash devuelve el resumen de todo el árbol. Dos árboles con el mismo
+// Hasher y los mismos valores tienen el mismo resumen.
+func (tree *RBTree) RootHash() uint64 {
+      return tree.aggPart(tree.root, tree.hashPart().part).(digest).hash
+}
+
+// Un rango de llaves con límites exclusivos. Si HasLow (o HasHigh) es false,
+// el rango no tiene límite inferior (o superior).
+type KeyRange struct {
+      Low, High       interface{}
+      HasLow, HasHigh bool
+}
+
+// RangeHash devuelve el resumen de los valores del árbol dentro de pRange,
+// para compararlo con el mismo rango de otra réplica.
+func (tree *RBTree) RangeHash(pRange KeyRange) uint64 {
+      return tree.digestIn(tree.root, pRange, tree.hashPart()).hash
+}
+
+// digestIn devuelve el resumen de los valores del subárbol de node dentro de
+// pRange. Al separarse los caminos hacia cada límite, cada lado queda con un
+// solo límite, por lo que el costo es O(log n).
+func (tree *RBTree) digestIn(node *Node, pRange KeyRange, h hashPart) digest {
+      switch {
+      case node == nil:
+            return emptyDigest
+      case !pRange.HasLow && !pRange.HasHigh:
+            return tree.aggPart(node, h.part).(digest)
+      case pRange.HasLow && tree.cmp(node.value, pRange.Low) <= 0:
+            return tree.digestIn(node.right, pRange, h)
+      case pRange.HasHigh && tree.cmp(node.value, pRange.High) >= 0:
+            return tree.digestIn(node.left, pRange, h)
+      }
+      left, right := pRange, pRange
+      left.HasHigh, right.HasLow = false, false
+      return tree.digestIn(node.left, left, h).
+            concat(h.single(node.value)).
+            concat(tree.digestIn(node.right, right, h))
+}
+
+// DiffRanges devuelve, en orden, rangos de llaves donde a y b tienen valores
+// distintos, comparando resúmenes: se baja por a y solamente se revisan los
+// subárboles cuyo resumen no coincide con el del mismo rango en b. Cada rango
+// contiene a lo sumo un valor de a. Ambos árboles deben mantener resúmenes
+// (con NewMerkleTree o NewHashMonoid), con el mismo orden y el mismo Hasher.
+func DiffRanges(a, b *RBTree) []KeyRange {
+      hashA, hashB := a.hashPart(), b.hashPart()
+      ranges := []KeyRange{}
+
+      var walk func(*Node, KeyRange)
+      walk = func(node *Node, pRange KeyRange) {
+            if a.digestIn(node, KeyRange{}, hashA) == b.digestIn(b.root, pRange, hashB) {
+                  return
+            }
+            if node == nil {
+                  ranges = append(ranges, pRange)
+                  return
+            }
+
+            left, right := pRange, pRange
+            left.High, left.HasHigh = node.value, true
+            right.Low, right.HasLow = node.value, true
+            walk(node.left, left)
+
+            // El valor del nodo se compara aparte, en el rango entre sus
+            // vecinos dentro del subárbol, que no contiene otros valores de a.
+            other := b.lookup(node.value)
+            if other == nil || hashA.single(node.value) != hashB.single(other.value) {
+                  point := pRange
+                  if node.left != nil {
+                        point.Low, point.HasLow = a.getMax(node.left).value, true
+                  }
+                  if node.right != nil {
+                        point.High, point.HasHigh = a.getMin(node.right).value, true
+                  }
+                  ranges = append(ranges, point)
+            }
+
+            walk(node.right, right)
+      }
+      walk(a.root, KeyRange{})
+      return ranges
+}
@@ -0,0 +1,165 @@
+package redBlackTree
+
+import (
+      "hash/fnv"
+      "math/rand"
+      "testing"
+)
+
+// entryHasher resume la llave y el dato de una entrada, de modo que cambiar
+// el dato cambia el resumen.
+func entryHasher(pValue interface{}) uint64 {
+      e := pValue.(entry)
+      h := fnv.New64a()
+      h.Write([]byte(e.label))
+      return intHasher(e.key) ^ h.Sum64()
+}
+
+func newIntMerkle(pValues ...int) *RBTree {
+      tree := NewMerkleTree(IntCmp, intHasher)
+      for _, v := range pValues {
+            tree.Insert(v)
+      }
+      return tree
+}
+
+// inRange indica si pValue está dentro de pRange, cuyos límites son exclusivos.
+func inRange(pCmp Cmp, pRange KeyRange, pValue interface{}) bool {
+      return (!pRange.HasLow || pCmp(pValue, pRange.Low) > 0) &&
+            (!pRange.HasHigh || pCmp(pValue, pRange.High) < 0)
+}
+
+// El resumen depende solamente del contenido, no del orden de inserción ni
+// de los borrados que llevaron a él.
+func TestRootHashOrderIndependent(t *testing.T) {
+      r := rand.New(rand.NewSource(1))
+      values := r.Perm(300)
+      want := newIntMerkle(values...).RootHash()
+
+      for round := 0; round < 10; round++ {
+            r.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+            tree := newIntMerkle(values...)
+            // Se insertan y borran valores de más, para llegar a otra forma.
+            for i := 300; i < 350; i++ {
+                  tree.Insert(i)
+            }
+            for i := 349; i >= 300; i-- {
+                  tree.Delete(i)
+            }
+            if err := tree.Validate(); err != nil {
+                  t.Fatal(err)
+            }
+            if tree.RootHash() != want {
+                  t.Fatalf("ronda %d: RootHash() = %x, se esperaba %x", round, tree.RootHash(), want)
+            }
+      }
+}
+
+// Cualquier cambio de un solo valor altera el resumen.
+func TestRootHashDetectsChange(t *testing.T) {
+      base := func() *RBTree {
+            tree := NewMerkleTree(entryCmp, entryHasher)
+            for i := 0; i < 50; i++ {
+                  tree.Insert(entry{i, "x"})
+            }
+            return tree
+      }
+      want := base().RootHash()
+      if want == NewMerkleTree(entryCmp, entryHasher).RootHash() {
+            t.Fatal("el resumen no distingue el árbol vacío")
+      }
+      changes := map[string]func(*RBTree){
+            "Insert":         func(tree *RBTree) { tree.Insert(entry{50, "x"}) },
+            "Delete primero": func(tree *RBTree) { tree.Delete(entry{key: 0}) },
+            "Delete medio":   func(tree *RBTree) { tree.Delete(entry{key: 25}) },
+            "Replace":        func(tree *RBTree) { tree.Replace(entry{25, "y"}) },
+      }
+      for name, change := range changes {
+            tree := base()
+            change(tree)
+            if tree.RootHash() == want {
+                  t.Errorf("%s: el resumen no cambió", name)
+            }
+      }
+}
+
+// RangeHash coincide con el resumen de un árbol que tiene solamente los
+// valores del rango.
+func TestRangeHash(t *testing.T) {
+      r := rand.New(rand.NewSource(2))
+      values := r.Perm(200)[:120]
+      tree := newIntMerkle(values...)
+
+      for i := 0; i < 300; i++ {
+            pRange := KeyRange{Low: r.Intn(220) - 10, High: r.Intn(220) - 10, HasLow: r.Intn(4) > 0, HasHigh: r.Intn(4) > 0}
+            subset := newIntMerkle()
+            for _, v := range values {
+                  if inRange(IntCmp, pRange, v) {
+                        subset.Insert(v)
+                  }
+            }
+            if got, want := tree.RangeHash(pRange), subset.RootHash(); got != want {
+                  t.Fatalf("RangeHash(%+v) = %x, se esperaba %x", pRange, got, want)
+            }
+      }
+      if tree.RangeHash(KeyRange{}) != tree.RootHash() {
+            t.Fatal("RangeHash sin límites no es RootHash")
+      }
+}
+
+// DiffRanges devuelve rangos en orden que cubren exactamente las llaves
+// distintas: cada llave distinta cae en un rango, y cada rango contiene al
+// menos una.
+func TestDiffRangesExact(t *testing.T) {
+      r := rand.New(rand.NewSource(3))
+      for round := 0; round < 50; round++ {
+            a := NewMerkleTree(entryCmp, entryHasher)
+            b := NewMerkleTree(entryCmp, entryHasher)
+            differing := map[int]bool{}
+            for key := 0; key < 100; key++ {
+                  switch r.Intn(20) {
+                  case 0:
+                        a.Insert(entry{key, "x"})
+                        differing[key] = true
+                  case 1:
+                        b.Insert(entry{key, "x"})
+                        differing[key] = true
+                  case 2:
+                        a.Insert(entry{key, "x"})
+                        b.Insert(entry{key, "y"})
+                        differing[key] = true
+                  case 3, 4:
+                  default:
+                        a.Insert(entry{key, "x"})
+                        b.Insert(entry{key, "x"})
+                  }
+            }
+
+            ranges := DiffRanges(a, b)
+            covered := map[int]bool{}
+            for i, pRange := range ranges {
+                  // Los rangos pueden traslaparse, pero empiezan en orden.
+                  if i > 0 && ranges[i-1].HasLow && (!pRange.HasLow || entryCmp(ranges[i-1].Low, pRange.Low) > 0) {
+                        t.Fatalf("ronda %d: los rangos %+v y %+v no están en orden", round, ranges[i-1], pRange)
+                  }
+                  found := false
+                  for key := range differing {
+                        if inRange(entryCmp, pRange, entry{key: key}) {
+                              covered[key] = true
+                              found = true
+                        }
+                  }
+                  if !found {
+                        t.Fatalf("ronda %d: el rango %+v no tiene llaves distintas", round, pRange)
+                  }
+            }
+            if len(covered) != len(differing) {
+                  t.Fatalf("ronda %d: los rangos cubren %d de %d llaves distintas", round, len(covered), len(differing))
+            }
+      }
+
+      same := newIntMerkle(1, 2, 3)
+      if ranges := DiffRanges(same, newIntMerkle(3, 1, 2)); len(ranges) != 0 {
+            t.Fatalf("DiffRanges de árboles iguales = %+v", ranges)
+      }
+}
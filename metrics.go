@@ -0,0 +1,183 @@
+/* Métricas del costo de las operaciones del árbol.
+   Cuentan las rotaciones, los cambios de color y las comparaciones, en total
+   y por cada Insert, Delete, Find y Clear. Si el árbol no tiene métricas,
+   cada punto de medición cuesta solamente una revisión de nil.
+*/
+
+package redBlackTree
+
+import (
+      "expvar"
+      "math/bits"
+      "sync/atomic"
+)
+
+// Operaciones que se miden por separado.
+const (
+      opInsert = iota
+      opDelete
+      opFind
+      opClear
+      opCount
+)
+
+var opNames = [opCount]string{"insert", "delete", "find", "clear"}
+
+// Cantidad de cubetas de los histogramas. La cubeta 0 cuenta las operaciones
+// con costo 0 y la cubeta i > 0 las de costo entre 2^(i-1) y 2^i - 1; la
+// última incluye todo lo mayor.
+const histogramBuckets = 20
+
+// Histograma del costo por operación, en cubetas de potencias de dos.
+type Histogram struct {
+      buckets [histogramBuckets]atomic.Int64
+}
+
+func (h *Histogram) observe(cost int64) {
+      bucket := bits.Len64(uint64(cost))
+      if bucket >= histogramBuckets {
+            bucket = histogramBuckets - 1
+      }
+      h.buckets[bucket].Add(1)
+}
+
+// Buckets devuelve la cantidad de operaciones en cada cubeta.
+func (h *Histogram) Buckets() []int64 {
+      counts := make([]int64, histogramBuckets)
+      for i := range h.buckets {
+            counts[i] = h.buckets[i].Load()
+      }
+      return counts
+}
+
+// Métricas de un tipo de operación: cuántas veces se ejecutó y los
+// histogramas de lo que costó cada una.
+type OpMetrics struct {
+      Count       atomic.Int64
+      Comparisons Histogram
+      Rotations   Histogram
+      Recolorings Histogram
+}
+
+// Metrics acumula los contadores de uno o más árboles. Los contadores son
+// atómicos, así que pueden leerse desde otra goroutine (por ejemplo, mediante
+// expvar) mientras el árbol se usa. Comparisons cuenta las comparaciones de
+// Insert, Delete y Find, incluidas sus versiones Try.
+type Metrics struct {
+      RotationsLeft  atomic.Int64
+      RotationsRight atomic.Int64
+      Recolorings    atomic.Int64
+      Comparisons    atomic.Int64
+      ops            [opCount]OpMetrics
+}
+
+// Op devuelve las métricas de la operación indicada ("insert", "delete",
+// "find" o "clear"), o nil si no existe.
+func (m *Metrics) Op(name string) *OpMetrics {
+      for i, opName := range opNames {
+            if opName == name {
+                  return &m.ops[i]
+            }
+      }
+      return nil
+}
+
+// Costo de una operación, o el acumulado por un árbol.
+type opCost struct {
+      comparisons, rotations, recolorings int64
+}
+
+// observe registra en los histogramas de op el costo de una operación.
+func (m *Metrics) observe(op int, cost opCost) {
+      metrics := &m.ops[op]
+      metrics.Count.Add(1)
+      metrics.Comparisons.observe(cost.comparisons)
+      metrics.Rotations.observe(cost.rotations)
+      metrics.Recolorings.observe(cost.recolorings)
+}
+
+// observe registra la operación op con lo que costó al árbol desde start.
+// Como el costo se toma de tree.cost y no de los totales de las métricas,
+// los árboles que las comparten no mezclan sus operaciones.
+func (tree *RBTree) observe(op int, start opCost) {
+      tree.metrics.observe(op, opCost{
+            comparisons: tree.cost.comparisons - start.comparisons,
+            rotations:   tree.cost.rotations - start.rotations,
+            recolorings: tree.cost.recolorings - start.recolorings,
+      })
+}
+
+// countComparisons suma n comparaciones si las métricas están activas.
+func (tree *RBTree) countComparisons(n int64) {
+      if tree.metrics != nil {
+            tree.metrics.Comparisons.Add(n)
+            tree.cost.comparisons += n
+      }
+}
+
+// SetMetrics hace que el árbol acumule sus contadores en pMetrics, que puede
+// compartirse entre árboles: los totales suman los de todos, y el costo de
+// cada operación se mide con contadores propios del árbol. Con nil se dejan
+// de medir.
+func (tree *RBTree) SetMetrics(pMetrics *Metrics) {
+      tree.metrics = pMetrics
+}
+
+// Metrics devuelve las métricas del árbol, o nil si no tiene.
+func (tree *RBTree) Metrics() *Metrics {
+      return tree.metrics
+}
+
+// paint cambia el color del nodo y cuenta el cambio si las métricas están activas.
+func (tree *RBTree) paint(node *Node, pColor Color) {
+      if tree.metrics != nil && node.color != pColor {
+            tree.metrics.Recolorings.Add(1)
+            tree.cost.recolorings++
+      }
+      node.color = pColor
+}
+
+// Resumen de las métricas como se publica en expvar.
+type opSummary struct {
+      Count       int64   `json:"count"`
+      Comparisons []int64 `json:"comparisons"`
+      Rotations   []int64 `json:"rotations"`
+      Recolorings []int64 `json:"recolorings"`
+}
+
+type metricsSummary struct {
+      RotationsLeft  int64                `json:"rotations_left"`
+      RotationsRight int64                `json:"rotations_right"`
+      Recolorings    int64                `json:"recolorings"`
+      Comparisons    int64                `json:"comparisons"`
+      Ops            map[string]opSummary `json:"ops"`
+}
+
+func (m *Metrics) summary() metricsSummary {
+      summary := metricsSummary{
+            RotationsLeft:  m.RotationsLeft.Load(),
+            RotationsRight: m.RotationsRight.Load(),
+            Recolorings:    m.Recolorings.Load(),
+            Comparisons:    m.Comparisons.Load(),
+            Ops:            map[string]opSummary{},
+      }
+      for i, name := range opNames {
+            op := &m.ops[i]
+            summary.Ops[name] = opSummary{
+                  Count:       op.Count.Load(),
+                  Comparisons: op.Comparisons.Buckets(),
+                  Rotations:   op.Rotations.Buckets(),
+                  Recolorings: op.Recolorings.Buckets(),
+            }
+      }
+      return summary
+}
+
+// Publish publica las métricas como la variable expvar pName, que se
+// despliega en JSON en /debug/vars. Como expvar.Publish, entra en pánico si
+// ya existe una variable con ese nombre.
+func (m *Metrics) Publish(pName string) {
+      expvar.Publish(pName, expvar.Func(func() interface{} {
+            return m.summary()
+      }))
+}
@@ -0,0 +1,101 @@
+package redBlackTree
+
+import (
+      "reflect"
+      "testing"
+)
+
+// buckets arma el histograma esperado a partir de los costos observados.
+func buckets(pCosts ...int64) []int64 {
+      h := &Histogram{}
+      for _, cost := range pCosts {
+            h.observe(cost)
+      }
+      return h.Buckets()
+}
+
+// checkOp revisa la cantidad de operaciones y los histogramas de op.
+func checkOp(t *testing.T, m *Metrics, pName string, pCount int64, pComparisons, pRotations, pRecolorings []int64) {
+      t.Helper()
+      op := m.Op(pName)
+      if op.Count.Load() != pCount {
+            t.Errorf("%s: Count = %d, se esperaba %d", pName, op.Count.Load(), pCount)
+      }
+      for _, h := range []struct {
+            name      string
+            got, want []int64
+      }{
+            {"Comparisons", op.Comparisons.Buckets(), pComparisons},
+            {"Rotations", op.Rotations.Buckets(), pRotations},
+            {"Recolorings", op.Recolorings.Buckets(), pRecolorings},
+      } {
+            if !reflect.DeepEqual(h.got, h.want) {
+                  t.Errorf("%s: %s = %v, se esperaba %v", pName, h.name, h.got, h.want)
+            }
+      }
+}
+
+// Insertar 1, 2 y 3 en orden cuesta:
+//   - 1: ninguna comparación; la raíz se pinta de negro.
+//   - 2: una comparación con la raíz.
+//   - 3: dos comparaciones, una rotación a la izquierda y dos cambios de
+//     color (2 a negro y 1 a rojo).
+// Después Find(3) y Delete(1), que es una hoja roja, comparan dos veces.
+func TestMetricsExactCounts(t *testing.T) {
+      m := &Metrics{}
+      tree := NewTree(IntCmp)
+      tree.SetMetrics(m)
+      for _, v := range []int{1, 2, 3} {
+            tree.Insert(v)
+      }
+      tree.Find(3)
+      tree.Delete(1)
+      tree.Clear()
+
+      if m.Comparisons.Load() != 7 || m.RotationsLeft.Load() != 1 || m.RotationsRight.Load() != 0 || m.Recolorings.Load() != 3 {
+            t.Fatalf("totales: %d comparaciones, %d y %d rotaciones, %d cambios de color",
+                  m.Comparisons.Load(), m.RotationsLeft.Load(), m.RotationsRight.Load(), m.Recolorings.Load())
+      }
+      checkOp(t, m, "insert", 3, buckets(0, 1, 2), buckets(0, 0, 1), buckets(1, 0, 2))
+      checkOp(t, m, "find", 1, buckets(2), buckets(0), buckets(0))
+      checkOp(t, m, "delete", 1, buckets(2), buckets(0), buckets(0))
+      checkOp(t, m, "clear", 1, buckets(0), buckets(0), buckets(0))
+
+      // Sin métricas no se cuenta nada más.
+      tree.SetMetrics(nil)
+      tree.Insert(4)
+      if m.Comparisons.Load() != 7 || m.Op("insert").Count.Load() != 3 {
+            t.Fatal("se contó una operación sin métricas")
+      }
+}
+
+// Dos árboles comparten las métricas y las operaciones de uno ocurren en
+// medio de las del otro, pues su comparador busca en el otro árbol. Los
+// totales suman los de ambos, pero el costo de cada operación es el del
+// árbol que la hizo.
+func TestMetricsShared(t *testing.T) {
+      m := &Metrics{}
+      other := NewTree(IntCmp)
+      for i := 0; i < 100; i++ {
+            other.Insert(i)
+      }
+      other.SetMetrics(m)
+      finds := int64(0)
+      tree := NewTree(func(o1, o2 interface{}) int {
+            finds++
+            other.Find(int(finds % 100))
+            return IntCmp(o1, o2)
+      })
+      tree.SetMetrics(m)
+
+      for _, v := range []int{1, 2, 3} {
+            tree.Insert(v)
+      }
+      tree.Delete(1)
+
+      checkOp(t, m, "insert", 3, buckets(0, 1, 2), buckets(0, 0, 1), buckets(1, 0, 2))
+      checkOp(t, m, "delete", 1, buckets(2), buckets(0), buckets(0))
+      if m.Op("find").Count.Load() != finds || finds != 5 {
+            t.Fatalf("find: Count = %d, se esperaba %d", m.Op("find").Count.Load(), finds)
+      }
+}
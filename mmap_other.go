@@ -0,0 +1,16 @@
+//go:build !unix
+
+package redBlackTree
+
+import (
+      "os"
+)
+
+// mapFile lee el archivo completo a memoria en los sistemas sin mmap.
+func mapFile(pPath string) ([]byte, func() error, error) {
+      data, err := os.ReadFile(pPath)
+      if err != nil {
+            return nil, nil, err
+      }
+      return data, func() error { return nil }, nil
+}
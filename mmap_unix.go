@@ -0,0 +1,33 @@
+//go:build unix
+
+package redBlackTree
+
+import (
+      "os"
+      "syscall"
+)
+
+// mapFile proyecta el archivo completo en memoria, de solo lectura, y devuelve
+// la función que deshace la proyección.
+func mapFile(pPath string) ([]byte, func() error, error) {
+      f, err := os.Open(pPath)
+      if err != nil {
+            return nil, nil, err
+      }
+      defer f.Close()
+
+      info, err := f.Stat()
+      if err != nil {
+            return nil, nil, err
+      }
+      // mmap no acepta archivos vacíos; newFlatTree los rechaza de todas formas.
+      if info.Size() == 0 {
+            return []byte{}, func() error { return nil }, nil
+      }
+
+      data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+      if err != nil {
+            return nil, nil, err
+      }
+      return data, func() error { return syscall.Munmap(data) }, nil
+}
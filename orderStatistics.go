@@ -0,0 +1,72 @@
+/* Estadísticos de orden: la posición de un valor (Rank) y el valor en una
+   posición (Select), en O(log n). Usan el tamaño de cada subárbol, que
+   mantienen los árboles aumentados con CountMonoid, solo o dentro de Monoids.
+*/
+
+package redBlackTree
+
+// Se define un nuevo árbol cuyos nodos guardan el tamaño de su subárbol,
+// para usar Rank y Select.
+func NewOrderStatisticTree(pCmp Cmp) *RBTree {
+      return NewAugmentedTree(pCmp, CountMonoid{})
+}
+
+// isCount indica si el monoide es CountMonoid, para usarlo con findMonoid.
+func isCount(m Monoid) bool {
+      _, ok := m.(CountMonoid)
+      return ok
+}
+
+// mustCount devuelve la posición de CountMonoid en el monoide del árbol (ver
+// findMonoid) y entra en pánico si el árbol no lo tiene.
+func (tree *RBTree) mustCount() int {
+      _, i, ok := tree.findMonoid(isCount)
+      if !ok {
+            panic("El árbol no guarda el tamaño de los subárboles")
+      }
+      return i
+}
+
+// Rank devuelve la cantidad de valores del árbol menores que pKey, que es la
+// posición (desde 0) de pKey si está en el árbol.
+func (tree *RBTree) Rank(pKey interface{}) int {
+      part := tree.mustCount()
+      tree.mustCheckType(pKey)
+      return tree.countBelow(pKey, part)
+}
+
+// countBelow devuelve la cantidad de valores menores que pKey, con los
+// tamaños de los subárboles guardados en la posición part (ver findMonoid).
+func (tree *RBTree) countBelow(pKey interface{}, part int) int {
+      rank := 0
+      node := tree.root
+      for node != nil {
+            if tree.cmp(pKey, node.value) <= 0 {
+                  node = node.left
+            } else {
+                  rank += tree.aggPart(node.left, part).(int) + 1
+                  node = node.right
+            }
+      }
+      return rank
+}
+
+// Select devuelve el nodo con el i-ésimo valor en orden (desde 0), o nil si
+// i está fuera del árbol.
+func (tree *RBTree) Select(i int) *Node {
+      part := tree.mustCount()
+      node := tree.root
+      for node != nil {
+            left := tree.aggPart(node.left, part).(int)
+            switch {
+            case i < left:
+                  node = node.left
+            case i > left:
+                  i -= left + 1
+                  node = node.right
+            default:
+                  return node
+            }
+      }
+      return nil
+}
@@ -0,0 +1,121 @@
+/* Consultas por prefijo para árboles de hileras.
+   Con StringCmp las hileras se ordenan byte a byte, así que todas las que
+   empiezan con un prefijo forman un rango contiguo [prefijo, fin), donde fin
+   es la primera hilera mayor que cualquier otra con ese prefijo. Estos
+   métodos solamente tienen sentido para árboles creados con StringCmp.
+*/
+
+package redBlackTree
+
+// prefixEnd devuelve la hilera más pequeña que es mayor que todas las que
+// empiezan con pPrefix: se incrementa el último byte que no sea 0xff y se
+// descarta el resto. Si no existe (el prefijo es vacío o solamente tiene
+// bytes 0xff), el rango no tiene límite superior y se devuelve false.
+func prefixEnd(pPrefix string) (string, bool) {
+      end := []byte(pPrefix)
+      for i := len(end) - 1; i >= 0; i-- {
+            if end[i] < 0xff {
+                  end[i]++
+                  return string(end[:i+1]), true
+            }
+      }
+      return "", false
+}
+
+// scanPrefix aplica visit en in-order a los nodos cuyo valor empieza con
+// pPrefix, visitando solamente las ramas que pueden contenerlos.
+func (tree *RBTree) scanPrefix(pPrefix string, visit func(*Node)) {
+      end, bounded := prefixEnd(pPrefix)
+
+      var walk func(*Node)
+      walk = func(node *Node) {
+            if node == nil {
+                  return
+            }
+            afterStart := tree.cmp(node.value, pPrefix) >= 0
+            beforeEnd := !bounded || tree.cmp(node.value, end) < 0
+
+            // Si el valor es igual al prefijo, a la izquierda no hay nada más.
+            if afterStart && tree.cmp(node.value, pPrefix) > 0 {
+                  walk(node.left)
+            }
+            if afterStart && beforeEnd {
+                  visit(node)
+            }
+            if beforeEnd {
+                  walk(node.right)
+            }
+      }
+      walk(tree.root)
+}
+
+// PrefixScan devuelve, en orden, las hileras del árbol que empiezan con pPrefix.
+func (tree *RBTree) PrefixScan(pPrefix string) []string {
+      keys := []string{}
+      tree.scanPrefix(pPrefix, func(node *Node) {
+            keys = append(keys, node.value.(string))
+      })
+      return keys
+}
+
+// CountPrefix devuelve la cantidad de hileras del árbol que empiezan con
+// pPrefix. Si el árbol guarda el tamaño de los subárboles (CountMonoid, solo
+// o en Monoids) cuesta O(log n); si no, recorre las k hileras que empiezan con
+// pPrefix y cuesta O(log n + k).
+func (tree *RBTree) CountPrefix(pPrefix string) int {
+      if _, part, ok := tree.findMonoid(isCount); ok {
+            end, bounded := prefixEnd(pPrefix)
+            below := tree.count
+            if bounded {
+                  below = tree.countBelow(end, part)
+            }
+            return below - tree.countBelow(pPrefix, part)
+      }
+
+      count := 0
+      tree.scanPrefix(pPrefix, func(*Node) {
+            count++
+      })
+      return count
+}
+
+// NextAfterPrefix devuelve la primera hilera del árbol mayor que todas las que
+// empiezan con pPrefix, y false si no existe.
+func (tree *RBTree) NextAfterPrefix(pPrefix string) (string, bool) {
+      end, bounded := prefixEnd(pPrefix)
+      if !bounded {
+            return "", false
+      }
+
+      // Se busca el menor valor mayor o igual a end.
+      var next *Node
+      node := tree.root
+      for node != nil {
+            if tree.cmp(node.value, end) >= 0 {
+                  next = node
+                  node = node.left
+            } else {
+                  node = node.right
+            }
+      }
+      if next == nil {
+            return "", false
+      }
+      return next.value.(string), true
+}
+
+// LongestCommonPrefix devuelve el prefijo más largo que comparten todas las
+// hileras del árbol. Como están ordenadas, basta con comparar la menor y la
+// mayor. Para un árbol vacío devuelve la hilera vacía.
+func (tree *RBTree) LongestCommonPrefix() string {
+      if tree.root == nil {
+            return ""
+      }
+      first, last := tree.Min().value.(string), tree.Max().value.(string)
+
+      i := 0
+      for i < len(first) && i < len(last) && first[i] == last[i] {
+            i++
+      }
+      return first[:i]
+}
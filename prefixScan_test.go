@@ -0,0 +1,110 @@
+package redBlackTree
+
+import (
+      "math/rand"
+      "reflect"
+      "strings"
+      "testing"
+)
+
+// Compara PrefixScan, CountPrefix y NextAfterPrefix con un recorrido de todos
+// los valores, en un árbol común y en uno que guarda el tamaño de los
+// subárboles. El alfabeto incluye 0xff para los prefijos sin fin.
+func TestPrefixQueriesMatchScan(t *testing.T) {
+      alphabet := []string{"a", "b", "\xff"}
+      r := rand.New(rand.NewSource(1))
+      word := func() string {
+            var b strings.Builder
+            for n := r.Intn(4); n > 0; n-- {
+                  b.WriteString(alphabet[r.Intn(len(alphabet))])
+            }
+            return b.String()
+      }
+
+      for _, tree := range []*RBTree{NewTree(StringCmp), NewOrderStatisticTree(StringCmp)} {
+            for i := 0; i < 40; i++ {
+                  tree.Insert(word())
+            }
+            values := tree.contents()
+            for i := 0; i < 200; i++ {
+                  prefix := word()
+                  want := []string{}
+                  next, hasNext := "", false
+                  for _, value := range values {
+                        s := value.(string)
+                        switch {
+                        case strings.HasPrefix(s, prefix):
+                              want = append(want, s)
+                        case s > prefix && !hasNext:
+                              next, hasNext = s, true
+                        }
+                  }
+
+                  if got := tree.PrefixScan(prefix); !reflect.DeepEqual(got, want) {
+                        t.Fatalf("PrefixScan(%q) = %q, se esperaba %q", prefix, got, want)
+                  }
+                  if got := tree.CountPrefix(prefix); got != len(want) {
+                        t.Fatalf("CountPrefix(%q) = %d, se esperaba %d", prefix, got, len(want))
+                  }
+                  if got, ok := tree.NextAfterPrefix(prefix); got != next || ok != hasNext {
+                        t.Fatalf("NextAfterPrefix(%q) = %q, %v; se esperaba %q, %v", prefix, got, ok, next, hasNext)
+                  }
+            }
+      }
+}
+
+func TestPrefixEdgeCases(t *testing.T) {
+      tree := NewOrderStatisticTree(StringCmp)
+      for _, s := range []string{"casa", "casado", "caso", "cosa", "dado"} {
+            tree.Insert(s)
+      }
+
+      // El prefijo vacío abarca todo el árbol y no tiene siguiente.
+      if got := tree.PrefixScan(""); len(got) != 5 {
+            t.Fatalf("PrefixScan(\"\") = %q", got)
+      }
+      if tree.CountPrefix("") != 5 {
+            t.Fatalf("CountPrefix(\"\") = %d", tree.CountPrefix(""))
+      }
+      if _, ok := tree.NextAfterPrefix(""); ok {
+            t.Fatal("NextAfterPrefix(\"\") encontró un valor")
+      }
+
+      // Un valor igual al prefijo también empieza con él.
+      if got := tree.PrefixScan("casa"); !reflect.DeepEqual(got, []string{"casa", "casado"}) {
+            t.Fatalf("PrefixScan(\"casa\") = %q", got)
+      }
+      if next, ok := tree.NextAfterPrefix("cas"); !ok || next != "cosa" {
+            t.Fatalf("NextAfterPrefix(\"cas\") = %q, %v", next, ok)
+      }
+      // No hay ninguna hilera después de las que empiezan con "d".
+      if _, ok := tree.NextAfterPrefix("d"); ok {
+            t.Fatal("NextAfterPrefix(\"d\") encontró un valor")
+      }
+      if got := tree.PrefixScan("x"); len(got) != 0 || tree.CountPrefix("x") != 0 {
+            t.Fatalf("PrefixScan(\"x\") = %q", got)
+      }
+}
+
+func TestLongestCommonPrefix(t *testing.T) {
+      tests := []struct {
+            values []string
+            want   string
+      }{
+            {nil, ""},
+            {[]string{"casa"}, "casa"},
+            {[]string{"casa", "casado", "caso"}, "cas"},
+            {[]string{"casa", "cosa"}, "c"},
+            {[]string{"casa", "dado"}, ""},
+            {[]string{"", "casa"}, ""},
+      }
+      for _, test := range tests {
+            tree := NewTree(StringCmp)
+            for _, s := range test.values {
+                  tree.Insert(s)
+            }
+            if got := tree.LongestCommonPrefix(); got != test.want {
+                  t.Errorf("LongestCommonPrefix(%q) = %q, se esperaba %q", test.values, got, test.want)
+            }
+      }
+}
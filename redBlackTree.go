@@ -7,6 +7,10 @@ package redBlackTree
 
 import (
       "fmt"
+      "io"
+      "log/slog"
+      "os"
+      "reflect"
       "strings"
 )
 
@@ -49,9 +53,18 @@ type Node struct {
       left   *Node
       right  *Node
       parent *Node
+      // Información adicional que mantienen los árboles aumentados sobre el
+      // subárbol que empieza en el nodo (por ejemplo, el extremo máximo en el
+      // árbol de intervalos).
+      aug    interface{}
 }
 
-// Getters y setters - como value puede ser más complicado no se indica su getter.
+// Getters y setters - value se devuelve como interface{}, por lo que quien lo use
+// debe conocer el tipo que almacena el árbol.
+
+func (pNode *Node) Value() interface{} {
+      return pNode.value
+}
 
 func (pNode *Node) Color() Color {
       return pNode.color
@@ -108,6 +121,7 @@ func(pNode *Node) clear() {
       pNode.left = nil
       pNode.color = false
       pNode.value = nil
+      pNode.aug = nil
 }
 
 // Es necesario que se defina un método de comparación entre los contenidos del árbol rojinegro,
@@ -151,12 +165,65 @@ type RBTree struct {
       root *Node
       cmp   Cmp
       count int
+      // Si no es nula, update recalcula el campo aug de un nodo a partir de sus
+      // hijos. Se llama cada vez que cambia el subárbol de un nodo.
+      update func(*Node)
+      // Monoide de los árboles creados con NewAugmentedTree.
+      monoid Monoid
+      // Tipo de los valores de los árboles creados con NewTreeOf.
+      valueType reflect.Type
+      // Asignador de nodos; si es nulo se crea cada nodo con new.
+      alloc Allocator
+      // Logger para los mensajes de diagnóstico; si es nulo no se registran.
+      logger *slog.Logger
+      // Métricas del árbol, si se activaron con SetMetrics, y el costo
+      // acumulado por este árbol, con el que se mide cada operación aunque
+      // las métricas se compartan.
+      metrics *Metrics
+      cost    opCost
+      // Bitácora para deshacer y rehacer, si se activó con SetJournal.
+      journal *journal
+      // Funciones suscritas a los cambios del árbol.
+      observers observers
 }
 
 // Devuelve la raíz del árbol.
 func (tree *RBTree) Root() *Node {
       return tree.root
 }
+
+// SetLogger hace que el árbol registre con pLogger, en nivel Debug, los casos
+// por los que pasan las operaciones. Con nil (el valor por defecto) no se
+// registra nada.
+func (tree *RBTree) SetLogger(pLogger *slog.Logger) {
+      tree.logger = pLogger
+}
+
+// debug registra un mensaje de diagnóstico si el árbol tiene un logger.
+func (tree *RBTree) debug(msg string, args ...interface{}) {
+      if tree.logger != nil {
+            tree.logger.Debug(msg, args...)
+      }
+}
+
+// valueOf devuelve el valor del nodo, o nil si el nodo es nulo.
+func valueOf(node *Node) interface{} {
+      if node == nil {
+            return nil
+      }
+      return node.value
+}
+
+// Len devuelve la cantidad de valores en el árbol.
+func (tree *RBTree) Len() int {
+      return tree.count
+}
+
+// IsEmpty indica si el árbol no tiene valores.
+func (tree *RBTree) IsEmpty() bool {
+      return tree.root == nil
+}
+
 // Se define un nuevo árbol con un comparador y raíz nula.
 func NewTree(pCmp Cmp) *RBTree {
       tree := &RBTree{root: nil, cmp: pCmp, count: 0}
@@ -166,13 +233,15 @@ func NewTree(pCmp Cmp) *RBTree {
 // Método de inserción en el árbol, que introduce el nodo con valor pValue.
 // Este método solamente inserta el valor como en un árbol de búsqueda binario
 // y no se usa directamente.
-func (tree *RBTree) insertValue(pValue interface{}) *Node {
+func (tree *RBTree) insertValue(pValue interface{}, pCmp Cmp) *Node {
       // Si la raíz no existe, se inserta una nueva y se aumenta el contador
       // de nodos.
       if tree.root == nil {
-            node := &Node{value: pValue, color: NEGRO}
+            node := tree.newNode(pValue, nil)
+            node.color = NEGRO
             tree.root = node
             tree.count++
+            tree.updateNode(node)
             return node
       }
 
@@ -182,7 +251,8 @@ func (tree *RBTree) insertValue(pValue interface{}) *Node {
       for true {
             // Se compara el valor de entrada (int o hilera) para saber qué
             // lado se debe seguir (mayor o menor que la raíz).
-            compare := tree.cmp(pValue, parentNode.value)
+            compare := pCmp(pValue, parentNode.value)
+            tree.countComparisons(1)
 
             switch {
             // Si la comparación coincide, no se inserta el valor y se devuelve
@@ -191,21 +261,24 @@ func (tree *RBTree) insertValue(pValue interface{}) *Node {
                   return nil
             // Si es menor, se va por el lado izquierdo del árbol. Si el nodo actual
             // no tiene hijos, se inserta de inmediato, si no se hace a este nodo el
-            // nuevo padre.
-            case compare == -1 && parentNode.left == nil:
-                  n := &Node{value: pValue, parent: parentNode}
+            // nuevo padre. Se acepta cualquier resultado negativo o positivo, no
+            // solamente -1 y 1.
+            case compare < 0 && parentNode.left == nil:
+                  n := tree.newNode(pValue, parentNode)
                   parentNode.left = n
                   tree.count++
+                  tree.updatePath(n)
                   return n
-            case compare == -1 && parentNode.left != nil:
+            case compare < 0 && parentNode.left != nil:
                   parentNode = parentNode.left
             // Análogamente para la rama derecha.
-            case compare == 1 && parentNode.right == nil:
-                  n := &Node{value: pValue, parent: parentNode}
+            case compare > 0 && parentNode.right == nil:
+                  n := tree.newNode(pValue, parentNode)
                   parentNode.right = n
                   tree.count++
+                  tree.updatePath(n)
                   return n
-            case compare == 1 && parentNode.right != nil:
+            case compare > 0 && parentNode.right != nil:
                   parentNode = parentNode.right
             }
 
@@ -217,13 +290,28 @@ func (tree *RBTree) insertValue(pValue interface{}) *Node {
 // este método utiliza insertValue y devuelve falso si el valor ya se encuentra
 // en el árbol. Si no está en el árbol se inserta y devuelve true.
 func (tree *RBTree) Insert(pValue interface{}) bool {
-      node := tree.insertValue(pValue)
+      tree.mustCheckType(pValue)
+      return tree.insert(pValue, tree.cmp)
+}
+
+// insert hace la inserción comparando con pCmp, que es el comparador del
+// árbol o uno que lo envuelve (como en las operaciones Try).
+func (tree *RBTree) insert(pValue interface{}, pCmp Cmp) bool {
+      if tree.metrics != nil {
+            defer tree.observe(opInsert, tree.cost)
+      }
+      node := tree.insertValue(pValue, pCmp)
 
       // Si el método devuelve nil, significa que no se insertó nada (el valor ya
       // existe en el árbol).
       if node == nil {
             return false
       }
+      if tree.journal != nil {
+            tree.record(journalInsert, pValue)
+      }
+      // Se notifica al terminar, cuando el árbol ya está balanceado.
+      defer tree.notify(Event{Kind: Inserted, Value: pValue})
 
       // Cada nodo nuevo que se inserta debe ser rojo (más fácil revisar las violaciones
       // de las condiciones).
@@ -234,7 +322,7 @@ func (tree *RBTree) Insert(pValue interface{}) bool {
             switch {
             // Caso 1: N es la nueva raíz del árbol.
             case node.parent == nil:
-                  node.color = NEGRO
+                  tree.paint(node, NEGRO)
                   return true
             // Caso 2: el padre de N debe ser negro.
             case node.parent.color == NEGRO:
@@ -242,9 +330,9 @@ func (tree *RBTree) Insert(pValue interface{}) bool {
             // Caso 3: tanto padre como tío son rojos, ambos deben repintarse.
             // negro y el abuelo se vuelve rojo.
             case node.uncle() != nil && node.uncle().color == ROJO:
-                  node.parent.color = NEGRO
-                  node.uncle().color = NEGRO
-                  node.grandpa().color = ROJO
+                  tree.paint(node.parent, NEGRO)
+                  tree.paint(node.uncle(), NEGRO)
+                  tree.paint(node.grandpa(), ROJO)
                   node = node.grandpa()
             // Caso 4: padre rojo, tío negro.
             case node.isRight() && node.parent.isLeft():
@@ -255,13 +343,13 @@ func (tree *RBTree) Insert(pValue interface{}) bool {
                   node = node.right
             // Caso 5: padre rojo, tío negro.
             case node.isRight():
-                  node.parent.color = NEGRO
-                  node.parent.parent.color = ROJO
+                  tree.paint(node.parent, NEGRO)
+                  tree.paint(node.parent.parent, ROJO)
                   tree.rotLeft(node.parent.parent)
                   return true
             case node.isLeft():
-                  node.parent.color = NEGRO
-                  node.parent.parent.color = ROJO
+                  tree.paint(node.parent, NEGRO)
+                  tree.paint(node.parent.parent, ROJO)
                   tree.rotRight(node.parent.parent)
                   return true
             }
@@ -269,6 +357,25 @@ func (tree *RBTree) Insert(pValue interface{}) bool {
       panic("Inserción fallida")
 }
 
+// Replace cambia el valor igual a pValue, según el comparador, por pValue, sin
+// cambiar la forma del árbol. Devuelve el valor anterior, o false si no había
+// ninguno igual, en cuyo caso no inserta nada.
+func (tree *RBTree) Replace(pValue interface{}) (interface{}, bool) {
+      tree.mustCheckType(pValue)
+      node := tree.lookup(pValue)
+      if node == nil {
+            return nil, false
+      }
+      old := node.value
+      node.value = pValue
+      tree.updatePath(node)
+      if tree.journal != nil {
+            tree.record(journalReplace, old, pValue)
+      }
+      tree.notify(Event{Kind: Replaced, Value: pValue, Old: old})
+      return old, true
+}
+
 // Rotación a la derecha:
 /*
       Q             P
@@ -276,6 +383,10 @@ func (tree *RBTree) Insert(pValue interface{}) bool {
   A   B             B   C
 */
 func (tree *RBTree) rotRight(Q *Node) {
+      if tree.metrics != nil {
+            tree.metrics.RotationsRight.Add(1)
+            tree.cost.rotations++
+      }
       P := Q.left
       Q.left = P.right
       // Si P tiene hijo derecho, se lo pasa a Q.
@@ -299,6 +410,9 @@ func (tree *RBTree) rotRight(Q *Node) {
       // El hijo derecho de P es ahora Q y P es el padre de Q
       P.right = Q
       Q.parent = P
+      // Q quedó abajo, por lo que se actualiza antes que P.
+      tree.updateNode(Q)
+      tree.updateNode(P)
 }
 
 // Rotación a la izquierda:
@@ -308,6 +422,10 @@ func (tree *RBTree) rotRight(Q *Node) {
       B   C     A   B
 */
 func (tree *RBTree) rotLeft(P *Node) {
+      if tree.metrics != nil {
+            tree.metrics.RotationsLeft.Add(1)
+            tree.cost.rotations++
+      }
       Q := P.right
       P.right = Q.left
       // Si Q tiene hijo izquierdo, se lo pasa a P.
@@ -331,8 +449,27 @@ func (tree *RBTree) rotLeft(P *Node) {
       // El hijo izquierdo de Q es ahora P y Q es el padre de P
       Q.left = P
       P.parent = Q
+      // P quedó abajo, por lo que se actualiza antes que Q.
+      tree.updateNode(P)
+      tree.updateNode(Q)
+}
+
+// updateNode recalcula la información aumentada de node, si el árbol la usa.
+func (tree *RBTree) updateNode(node *Node) {
+      if tree.update != nil && node != nil {
+            tree.update(node)
+      }
 }
 
+// updatePath recalcula la información aumentada desde node hasta la raíz.
+func (tree *RBTree) updatePath(node *Node) {
+      if tree.update == nil {
+            return
+      }
+      for ; node != nil; node = node.parent {
+            tree.update(node)
+      }
+}
 
 // El iterador recorre todo el árbol y devuelve todos los nodos, en el orden requerido.
 type Iterator interface {
@@ -409,17 +546,19 @@ func (iter *PreorderIterator) Iterate(node *Node) <-chan *Node {
 
 // Determina si la llave pKey se encuentra entre los valores de los nodos del árbol, 
 // si lo encuentra devuelve true y si no, false, además del nodo que contiene el valor.
-// Find utiliza el iterador para obtener el valor solicitado.
+// Find compara con el comparador del árbol, de modo que funciona también con
+// valores que no se pueden comparar con == (como []byte) o que no son iguales
+// a sí mismos (como NaN).
 func (tree *RBTree) Find(pKey interface{}) (bool, *Node) {
-      // Recorre el árbol, obtiene los valores en un channel
-      // y compara el valor de pKey con el de cada nodo
-      iter := &InorderIterator{}
-      for node := range iter.Iterate(tree.root) {
-            if pKey == node.value {
-                  return true, node
-            }
+      tree.mustCheckType(pKey)
+      node, compares := tree.search(pKey, tree.cmp)
+      // Find puede ejecutarse a la vez que otras lecturas (como en
+      // ConcurrentTree), así que su costo no pasa por tree.cost.
+      if tree.metrics != nil {
+            tree.metrics.Comparisons.Add(compares)
+            tree.metrics.observe(opFind, opCost{comparisons: compares})
       }
-      return false, nil
+      return node != nil, node
 }
 
 // FindKey determina si el valor de pKey es parte de los valores en el árbol. Es un
@@ -429,6 +568,51 @@ func (tree *RBTree) FindKey(pKey interface{}) bool {
       return found
 }
 
+// Min devuelve el nodo con el valor más pequeño del árbol, o nil si está vacío.
+func (tree *RBTree) Min() *Node {
+      if tree.root == nil {
+            return nil
+      }
+      return tree.getMin(tree.root)
+}
+
+// Max devuelve el nodo con el valor más grande del árbol, o nil si está vacío.
+func (tree *RBTree) Max() *Node {
+      if tree.root == nil {
+            return nil
+      }
+      return tree.getMax(tree.root)
+}
+
+// Range devuelve, en in-order, los nodos cuyos valores están en el intervalo
+// cerrado [pLow, pHigh]. Solamente se visitan las ramas que pueden contener
+// valores dentro del intervalo.
+func (tree *RBTree) Range(pLow, pHigh interface{}) []*Node {
+      nodes := []*Node{}
+
+      var visit func(*Node)
+      visit = func(node *Node) {
+            if node == nil {
+                  return
+            }
+            low, high := tree.cmp(pLow, node.value), tree.cmp(node.value, pHigh)
+            // Solamente se baja por la izquierda si el límite inferior es menor
+            // al valor del nodo, y análogamente por la derecha.
+            if low < 0 {
+                  visit(node.left)
+            }
+            if low <= 0 && high <= 0 {
+                  nodes = append(nodes, node)
+            }
+            if high < 0 {
+                  visit(node.right)
+            }
+      }
+      visit(tree.root)
+
+      return nodes
+}
+
 // Despliega los elementos del árbol en in-order, para mostrarlos mediante fmt.Print().
 // La hilera resultante se obtiene de recorrer el árbol mediante el iterador.
 func (tree *RBTree) String() string {
@@ -442,11 +626,23 @@ func (tree *RBTree) String() string {
       return s
 }
 
-// Clear borra completamente el árbol mediante deleteAll. 
+// Clear borra completamente el árbol mediante deleteAll, o devolviendo los
+// nodos al asignador si el árbol tiene uno.
 func (tree *RBTree) Clear() {
-      deleteAll(tree.root)
+      if tree.metrics != nil {
+            defer tree.observe(opClear, tree.cost)
+      }
+      if tree.journal != nil && !tree.journal.replaying {
+            tree.record(journalClear, tree.contents()...)
+      }
+      if tree.alloc != nil {
+            tree.alloc.FreeAll(tree.root)
+      } else {
+            deleteAll(tree.root)
+      }
       tree.root = nil
       tree.count = 0
+      tree.notify(Event{Kind: Cleared})
 }
 
 // deleteAll elimina los nodos recursivamente, mediante un recorrido en postorder
@@ -462,46 +658,60 @@ func deleteAll(node *Node) {
 // Delete elimina el nodo que coincide con el valor dado. No hace nada
 // si la llave no existe
 func (tree *RBTree) Delete(pKey interface{}) {
-      _, node := tree.Find(pKey)
+      tree.mustCheckType(pKey)
+      tree.remove(pKey, tree.cmp)
+}
+
+// remove hace el borrado comparando con pCmp y devuelve si la llave estaba.
+func (tree *RBTree) remove(pKey interface{}, pCmp Cmp) bool {
+      if tree.metrics != nil {
+            defer tree.observe(opDelete, tree.cost)
+      }
+      node, compares := tree.search(pKey, pCmp)
+      tree.countComparisons(compares)
+      if node == nil {
+            return false
+      }
+      if tree.journal != nil {
+            tree.record(journalDelete, node.value)
+      }
+      defer tree.notify(Event{Kind: Deleted, Value: node.value})
       tree.count--
       nodeCopy := node
-      fmt.Println("nodeCopy", nodeCopy)
       // Se guarda el color para revisar si existen violaciones por colores.
       copyColor := nodeCopy.color
-      fmt.Println("En delete: color original", copyColor)
-      var tempNode *Node
+      tree.debug("borrado", "valor", node.value, "color", copyColor)
+      // tempNode es el nodo que queda en la posición del nodo que se quitó y
+      // tempParent su padre. Se guarda el padre aparte porque tempNode puede
+      // ser nulo.
+      var tempNode, tempParent *Node
 
       // El borrado se maneja con varios casos, según la cantidad de hijos
       // que tenga el nodo por borrar y según el color de cada uno.
       // Ubica el único hijo donde estaba node originalmente.
       if node.left == nil {
             // Tiene un hijo derecho.
-            fmt.Println("Tiene 1 hijo derecho")
-            tempNode = node.right
+            tree.debug("borrado", "caso", "un hijo derecho")
+            tempNode, tempParent = node.right, node.parent
             tree.replace(node, node.right)
       } else if node.right == nil {
             // Tiene un hijo izquierdo.
-            fmt.Println("Tiene 1 hijo izquierdo")
-            tempNode = node.left
+            tree.debug("borrado", "caso", "un hijo izquierdo")
+            tempNode, tempParent = node.left, node.parent
             tree.replace(node, node.left)
       } else {
             // Tiene dos hijos.
-            fmt.Println("Tiene 2 hijos")
             nodeCopy = tree.getMin(node.right)
-            fmt.Println("nodeCopy", nodeCopy)
             tempNode = nodeCopy.right
-            fmt.Println("tempNode", tempNode)
             copyColor = nodeCopy.color
-            fmt.Println("En delete: color si tiene 2 hijos", copyColor)
+            tree.debug("borrado", "caso", "dos hijos", "sucesor", nodeCopy.value, "color", copyColor)
 
-            // Si nodeCopy es hijo de node, tempNode es hijo de nodeCopy
-            // si no es nulo.
+            // Si nodeCopy es hijo de node, tempNode sigue siendo hijo de nodeCopy.
             if nodeCopy.parent == node {
-                  if tempNode != nil {
-                        tempNode.parent = nodeCopy
-                  }
+                  tempParent = nodeCopy
             // Si nodeCopy no es hijo de node, se cambia nodeCopy por su hijo derecho.
             } else {
+                  tempParent = nodeCopy.parent
                   tree.replace(nodeCopy, nodeCopy.right)
                   nodeCopy.right = node.right
                   nodeCopy.right.parent = nodeCopy
@@ -510,15 +720,50 @@ func (tree *RBTree) Delete(pKey interface{}) {
             tree.replace(node, nodeCopy)
             nodeCopy.left = node.left
             nodeCopy.left.parent = nodeCopy
-            nodeCopy.color = node.color
-            fmt.Println("En delete: colores del nodo al final de dos hijos", node.color)
+            tree.paint(nodeCopy, node.color)
       }
+      node.clear()
+      tree.freeNode(node)
+      // Los subárboles cambiaron desde tempParent hacia arriba.
+      tree.updatePath(tempParent)
+
       // Se revisa que el borrado no viole ninguna regla del árbol. Si viola alguna regla,
       // se arregla allí.
-      fmt.Println("En delete: colores al final", copyColor)
       if copyColor == NEGRO {
-            tree.deleteFix(tempNode)
+            tree.deleteFix(tempNode, tempParent)
       }
+      return true
+}
+
+// lookup busca el nodo cuyo valor es igual a pKey según el comparador del árbol,
+// bajando por la rama correspondiente. Devuelve nil si no lo encuentra.
+func (tree *RBTree) lookup(pKey interface{}) *Node {
+      return tree.lookupWith(pKey, tree.cmp)
+}
+
+// lookupWith es como lookup, pero compara con pCmp.
+func (tree *RBTree) lookupWith(pKey interface{}, pCmp Cmp) *Node {
+      node, _ := tree.search(pKey, pCmp)
+      return node
+}
+
+// search hace la búsqueda de lookupWith y devuelve además cuántas veces
+// comparó, para las métricas.
+func (tree *RBTree) search(pKey interface{}, pCmp Cmp) (*Node, int64) {
+      node, compares := tree.root, int64(0)
+      for node != nil {
+            compares++
+            compare := pCmp(pKey, node.value)
+            switch {
+            case compare < 0:
+                  node = node.left
+            case compare > 0:
+                  node = node.right
+            default:
+                  return node, compares
+            }
+      }
+      return nil, compares
 }
 
 // replace se encarga de reubicar nodos, de modo que ubica a newNode en la
@@ -531,7 +776,8 @@ func (tree *RBTree) replace(oldNode *Node, newNode *Node) {
             oldNode.parent.left = newNode
       case oldNode == oldNode.parent.right:
             oldNode.parent.right = newNode
-      case oldNode != nil && newNode != nil:
+      }
+      if newNode != nil {
             newNode.parent = oldNode.parent
       }
 }
@@ -548,115 +794,251 @@ func (tree *RBTree) getMin(node *Node) *Node {
       }
 }
 
-// deleteFix arregla cualquier violación a las condiciones del árbol rojinegro
-// que pudieron surgir de modificar el árbol con delete.
-func (tree *RBTree) deleteFix(node *Node) {
-      fmt.Printf("Entra a arreglar nodo %s\n", node)
+// Para un nodo no nulo devuelve el valor más grande que puede obtenerse
+// desde node (el hijo derecho más abajo a partir de node).
+func (tree *RBTree) getMax(node *Node) *Node {
+      for {
+            if node.right != nil {
+                  node = node.right
+            } else {
+                  return node
+            }
+      }
+}
+
+// colorOf devuelve el color de node, tomando los hijos nulos como hojas negras.
+func colorOf(node *Node) Color {
       if node == nil {
-            return
+            return NEGRO
       }
+      return node.color
+}
+
+// deleteFix arregla cualquier violación a las condiciones del árbol rojinegro
+// que pudieron surgir de modificar el árbol con delete. node es el nodo que
+// quedó en la posición del borrado (puede ser nulo) y parent su padre.
+func (tree *RBTree) deleteFix(node *Node, parent *Node) {
+      tree.debug("arreglo de borrado", "valor", valueOf(node), "padre", valueOf(parent))
 loop:
       for {
             switch {
             // Los primeros dos casos son los más sencillos, pues no hay que arreglar nada.
             case node == tree.root:
-                  fmt.Println("es la raíz")
+                  tree.debug("arreglo de borrado", "caso", "raíz")
                   break loop
-            case node.color == ROJO:
-                  fmt.Println("listo, rojo")
+            case colorOf(node) == ROJO:
+                  tree.debug("arreglo de borrado", "caso", "nodo rojo")
                   break loop
             // Se tiene dos casos "espejo", cuando el hijo es derecho o izquierdo. En ambos
-            // casos se busca convertir los casos a casos más sencillos
-            case node == node.parent.right:
-                  fmt.Println("nodo es el hijo derecho")
-                  sibling := node.parent.left
+            // casos se busca convertir los casos a casos más sencillos. El hermano nunca es
+            // nulo, pues del lado de node falta un nodo negro.
+            case node == parent.right:
+                  tree.debug("arreglo de borrado", "caso", "hijo derecho", "padre", parent.value)
+                  sibling := parent.left
                   if sibling.color == ROJO {
-                        fmt.Printf("hermano rojo")
-                        sibling.color = NEGRO
-                        node.parent.color = ROJO
-                        tree.rotRight(node.parent)
-                        sibling = node.parent.left
+                        tree.debug("arreglo de borrado", "caso", "hermano rojo", "hermano", sibling.value)
+                        tree.paint(sibling, NEGRO)
+                        tree.paint(parent, ROJO)
+                        tree.rotRight(parent)
+                        sibling = parent.left
                   }
-                  if sibling != nil {
-                        switch {
-                        // 2 hijos negros.
-                        case sibling.left.color != ROJO && sibling.right.color != ROJO:
-                              fmt.Println("2 hijos negros")
-                              sibling.color = ROJO
-                              node = node.parent
-                        //  Hijo derecho rojo, hijo izquierdo negro.
-                        case sibling.right.color == ROJO && sibling.left.color != ROJO:
-                              fmt.Println("hijos derecho rojo, hijo izquierdo negro")
-                              sibling.right.color = NEGRO
-                              sibling.color = ROJO
-                              tree.rotLeft(sibling)
-                              sibling = node.parent.left
-                        }
-                        // Hijo izquierdo rojo
-                        if sibling.left.color == ROJO {
-                              fmt.Println("hijo izquierdo rojo")
-                              sibling.color = node.parent.color
-                              node.parent.color = NEGRO
-                              sibling.left.color = NEGRO
-                              tree.rotRight(node.parent)
-                              node = tree.root
-                        }
+                  switch {
+                  // 2 hijos negros.
+                  case colorOf(sibling.left) == NEGRO && colorOf(sibling.right) == NEGRO:
+                        tree.debug("arreglo de borrado", "caso", "hermano con dos hijos negros", "hermano", sibling.value)
+                        tree.paint(sibling, ROJO)
+                        node, parent = parent, parent.parent
+                        continue loop
+                  //  Hijo derecho rojo, hijo izquierdo negro.
+                  case colorOf(sibling.left) == NEGRO:
+                        tree.debug("arreglo de borrado", "caso", "hermano con hijo derecho rojo e izquierdo negro", "hermano", sibling.value)
+                        tree.paint(sibling.right, NEGRO)
+                        tree.paint(sibling, ROJO)
+                        tree.rotLeft(sibling)
+                        sibling = parent.left
                   }
+                  // Hijo izquierdo rojo
+                  tree.debug("arreglo de borrado", "caso", "hermano con hijo izquierdo rojo", "hermano", sibling.value)
+                  tree.paint(sibling, parent.color)
+                  tree.paint(parent, NEGRO)
+                  tree.paint(sibling.left, NEGRO)
+                  tree.rotRight(parent)
+                  node, parent = tree.root, nil
             // El caso simétrico, donde se cambia left por right en muchos casos.
-            case node == node.parent.left:
-                  fmt.Println("nodo es el hijo izquierdo")
-                  sibling := node.parent.right
+            default:
+                  tree.debug("arreglo de borrado", "caso", "hijo izquierdo", "padre", parent.value)
+                  sibling := parent.right
                   // Se rota para cambiar el caso y que sea contemplado por los siguientes condicionales
                   if sibling.color == ROJO {
-                        fmt.Printf("hermano rojo")
-                        sibling.color = NEGRO
-                        node.parent.color = ROJO
-                        tree.rotLeft(node.parent)
-                        sibling = node.parent.right
+                        tree.debug("arreglo de borrado", "caso", "hermano rojo", "hermano", sibling.value)
+                        tree.paint(sibling, NEGRO)
+                        tree.paint(parent, ROJO)
+                        tree.rotLeft(parent)
+                        sibling = parent.right
                   }
-                  if sibling != nil {
-                        switch {
-                        // 2 hijos negros
-                        case sibling.left.color != ROJO && sibling.right.color != ROJO:
-                              fmt.Println("2 hijos negros")
-                              sibling.color = ROJO
-                              node = node.parent
-                        // Hijo izquierdo rojo, hijo derecho negro
-                        case sibling.left.color == ROJO && sibling.right.color != ROJO:
-                              fmt.Println("hijos izquierdo rojo, hijo derecho negro")
-                              sibling.left.color = NEGRO
-                              sibling.color = ROJO
-                              tree.rotRight(sibling)
-                              sibling = node.parent.right
-                        }
-                        // Hijo derecho rojo
-                        if sibling.right.color == ROJO {
-                              fmt.Println("hijo derecho rojo")
-                              sibling.color = node.parent.color
-                              node.parent.color = NEGRO
-                              sibling.right.color = NEGRO
-                              tree.rotLeft(node.parent)
-                              node = tree.root
-                        }
+                  switch {
+                  // 2 hijos negros
+                  case colorOf(sibling.left) == NEGRO && colorOf(sibling.right) == NEGRO:
+                        tree.debug("arreglo de borrado", "caso", "hermano con dos hijos negros", "hermano", sibling.value)
+                        tree.paint(sibling, ROJO)
+                        node, parent = parent, parent.parent
+                        continue loop
+                  // Hijo izquierdo rojo, hijo derecho negro
+                  case colorOf(sibling.right) == NEGRO:
+                        tree.debug("arreglo de borrado", "caso", "hermano con hijo izquierdo rojo y derecho negro", "hermano", sibling.value)
+                        tree.paint(sibling.left, NEGRO)
+                        tree.paint(sibling, ROJO)
+                        tree.rotRight(sibling)
+                        sibling = parent.right
                   }
+                  // Hijo derecho rojo
+                  tree.debug("arreglo de borrado", "caso", "hermano con hijo derecho rojo", "hermano", sibling.value)
+                  tree.paint(sibling, parent.color)
+                  tree.paint(parent, NEGRO)
+                  tree.paint(sibling.right, NEGRO)
+                  tree.rotLeft(parent)
+                  node, parent = tree.root, nil
             }
       }
-      node.color = NEGRO
+      if node != nil {
+            tree.paint(node, NEGRO)
+      }
 }
 
 func (tree *RBTree) PrettyPrint() {
-      printChildren(tree.root, "")
+      tree.PrettyFprint(os.Stdout)
+}
+
+// PrettyFprint despliega el árbol igual que PrettyPrint, pero en el writer w.
+func (tree *RBTree) PrettyFprint(w io.Writer) {
+      if tree.root == nil {
+            fmt.Fprintln(w, "{}")
+            return
+      }
+      printChildren(w, tree.root, "")
 }
 
-func printChildren(node *Node, spaces string) {
-      fmt.Println(node)
+func printChildren(w io.Writer, node *Node, spaces string) {
+      fmt.Fprintln(w, node)
       spaces += "    "
       if node.left != nil {
-            fmt.Print(spaces, "|-- ")
-            printChildren(node.left, spaces)
+            fmt.Fprint(w, spaces, "|-- ")
+            printChildren(w, node.left, spaces)
       }
       if node.right != nil {
-            fmt.Print(spaces, "|-- ")
-            printChildren(node.right, spaces)
+            fmt.Fprint(w, spaces, "|-- ")
+            printChildren(w, node.right, spaces)
+      }
+}
+
+// Dot devuelve el árbol en el lenguaje DOT de Graphviz, con cada nodo pintado
+// de su color. Los hijos nulos se dibujan como hojas negras puntuales para que
+// se distinga el lado de cada hijo.
+func (tree *RBTree) Dot() string {
+      var b strings.Builder
+      b.WriteString("digraph RBTree {\n")
+      b.WriteString("      node [style=filled, fontcolor=white];\n")
+
+      iter := &PreorderIterator{}
+      ids := map[*Node]int{}
+      nils := 0
+      for node := range iter.Iterate(tree.root) {
+            ids[node] = len(ids)
+            fill := "black"
+            if node.color == ROJO {
+                  fill = "red"
+            }
+            fmt.Fprintf(&b, "      n%d [label=%q, fillcolor=%s];\n", ids[node], fmt.Sprint(node.value), fill)
+            if node.parent != nil {
+                  fmt.Fprintf(&b, "      n%d -> n%d;\n", ids[node.parent], ids[node])
+            }
+            // Las hojas nulas se agregan de una vez, pues el recorrido no las visita.
+            for _, child := range []*Node{node.left, node.right} {
+                  if child == nil {
+                        fmt.Fprintf(&b, "      nil%d [shape=point, fillcolor=black];\n", nils)
+                        fmt.Fprintf(&b, "      n%d -> nil%d;\n", ids[node], nils)
+                        nils++
+                  }
+            }
+      }
+
+      b.WriteString("}\n")
+      return b.String()
+}
+
+// Validate revisa que el árbol cumpla las condiciones de árbol de búsqueda y de
+// árbol rojinegro: raíz negra, ningún nodo rojo con hijo rojo, la misma cantidad
+// de nodos negros en todo camino hacia las hojas, punteros al padre consistentes
+// y el contador de nodos correcto. Devuelve nil si el árbol es válido.
+func (tree *RBTree) Validate() error {
+      if tree.root == nil {
+            if tree.count != 0 {
+                  return fmt.Errorf("árbol vacío con contador %d", tree.count)
+            }
+            return nil
+      }
+      if tree.root.parent != nil {
+            return fmt.Errorf("la raíz %v tiene padre", tree.root)
+      }
+      if tree.root.color != NEGRO {
+            return fmt.Errorf("la raíz %v no es negra", tree.root)
+      }
+
+      nodes := 0
+      // check devuelve la altura negra del subárbol con raíz en node.
+      var check func(*Node) (int, error)
+      check = func(node *Node) (int, error) {
+            if node == nil {
+                  return 1, nil
+            }
+            nodes++
+            for _, child := range []*Node{node.left, node.right} {
+                  if child == nil {
+                        continue
+                  }
+                  if child.parent != node {
+                        return 0, fmt.Errorf("el padre de %v no es %v", child, node)
+                  }
+                  if node.color == ROJO && child.color == ROJO {
+                        return 0, fmt.Errorf("el nodo rojo %v tiene un hijo rojo %v", node, child)
+                  }
+            }
+            left, err := check(node.left)
+            if err != nil {
+                  return 0, err
+            }
+            right, err := check(node.right)
+            if err != nil {
+                  return 0, err
+            }
+            if left != right {
+                  return 0, fmt.Errorf("alturas negras distintas bajo %v: %d y %d", node, left, right)
+            }
+            if node.color == NEGRO {
+                  left++
+            }
+            return left, nil
+      }
+
+      if _, err := check(tree.root); err != nil {
+            return err
+      }
+      if nodes != tree.count {
+            return fmt.Errorf("el contador indica %d nodos pero hay %d", tree.count, nodes)
+      }
+
+      // El recorrido in-order debe dar los valores en orden estrictamente creciente.
+      var prev *Node
+      iter := &InorderIterator{}
+      ch := iter.Iterate(tree.root)
+      for node := range ch {
+            if prev != nil && tree.cmp(prev.value, node.value) >= 0 {
+                  // Se vacía el canal para que la goroutine del iterador termine.
+                  for range ch {
+                  }
+                  return fmt.Errorf("el orden in-order falla entre %v y %v", prev, node)
+            }
+            prev = node
       }
+      return nil
 }
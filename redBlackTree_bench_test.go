@@ -0,0 +1,276 @@
+package redBlackTree
+
+import (
+      "fmt"
+      "math/rand"
+      "sort"
+      "testing"
+)
+
+// Asignadores que se comparan en las mediciones; nil es el asignador por
+// defecto, que crea cada nodo con new.
+var benchAllocators = []struct {
+      name string
+      new  func() Allocator
+}{
+      {"Default", func() Allocator { return nil }},
+      {"Pool", func() Allocator { return NewPoolAllocator() }},
+      {"Arena", func() Allocator { return NewArenaAllocator(0) }},
+}
+
+// Tamaños de los árboles en las mediciones.
+var benchSizes = []int{1000, 10000}
+
+// Órdenes de llaves: aleatorio, creciente (el peor caso para un árbol de
+// búsqueda sin balancear) y en zigzag desde los extremos hacia el centro, que
+// obliga a rebalancear en ambos lados del árbol.
+var keyOrders = []struct {
+      name string
+      keys func(n int) []int
+}{
+      {"Random", func(n int) []int {
+            return rand.New(rand.NewSource(1)).Perm(n)
+      }},
+      {"Sequential", func(n int) []int {
+            keys := make([]int, n)
+            for i := range keys {
+                  keys[i] = i
+            }
+            return keys
+      }},
+      {"Adversarial", func(n int) []int {
+            keys := make([]int, 0, n)
+            for low, high := 0, n-1; low <= high; low, high = low+1, high-1 {
+                  keys = append(keys, low)
+                  if low != high {
+                        keys = append(keys, high)
+                  }
+            }
+            return keys
+      }},
+}
+
+// fillTree crea un árbol de enteros con el asignador y las llaves dados.
+func fillTree(alloc Allocator, keys []int) *RBTree {
+      tree := newBenchTree(alloc)
+      for _, k := range keys {
+            tree.Insert(k)
+      }
+      return tree
+}
+
+// newBenchTree crea un árbol de enteros con el asignador dado (nil para el
+// asignador por defecto).
+func newBenchTree(alloc Allocator) *RBTree {
+      tree := NewTree(IntCmp)
+      if alloc != nil {
+            tree.SetAllocator(alloc)
+      }
+      return tree
+}
+
+// Cada iteración inserta y borra un valor sobre un árbol que ya tiene size
+// valores.
+func BenchmarkChurn(b *testing.B) {
+      for _, alloc := range benchAllocators {
+            for _, size := range benchSizes {
+                  b.Run(fmt.Sprintf("%s/%d", alloc.name, size), func(b *testing.B) {
+                        tree := newBenchTree(alloc.new())
+                        for i := 0; i < size; i++ {
+                              tree.Insert(2 * i)
+                        }
+                        b.ReportAllocs()
+                        b.ResetTimer()
+                        for i := 0; i < b.N; i++ {
+                              // Se inserta y se borra un valor impar, que no está en el árbol.
+                              v := 2*(i%size) + 1
+                              tree.Insert(v)
+                              tree.Delete(v)
+                        }
+                  })
+            }
+      }
+}
+
+// Cada iteración llena un árbol con size valores y lo vacía con Clear.
+func BenchmarkFillClear(b *testing.B) {
+      for _, alloc := range benchAllocators {
+            for _, size := range benchSizes {
+                  b.Run(fmt.Sprintf("%s/%d", alloc.name, size), func(b *testing.B) {
+                        tree := newBenchTree(alloc.new())
+                        b.ReportAllocs()
+                        for i := 0; i < b.N; i++ {
+                              for j := 0; j < size; j++ {
+                                    tree.Insert(j)
+                              }
+                              tree.Clear()
+                        }
+                  })
+            }
+      }
+}
+
+// runOrders ejecuta bench como sub-benchmark por cada orden de llaves,
+// asignador y tamaño.
+func runOrders(b *testing.B, bench func(b *testing.B, alloc func() Allocator, keys []int)) {
+      for _, order := range keyOrders {
+            for _, alloc := range benchAllocators {
+                  for _, size := range benchSizes {
+                        keys := order.keys(size)
+                        b.Run(fmt.Sprintf("%s/%s/%d", order.name, alloc.name, size), func(b *testing.B) {
+                              b.ReportAllocs()
+                              bench(b, alloc.new, keys)
+                        })
+                  }
+            }
+      }
+}
+
+// Cada iteración inserta todas las llaves en un árbol vacío.
+func BenchmarkInsert(b *testing.B) {
+      runOrders(b, func(b *testing.B, alloc func() Allocator, keys []int) {
+            for i := 0; i < b.N; i++ {
+                  fillTree(alloc(), keys)
+            }
+      })
+}
+
+// Cada iteración borra todas las llaves; el llenado no se mide.
+func BenchmarkDelete(b *testing.B) {
+      runOrders(b, func(b *testing.B, alloc func() Allocator, keys []int) {
+            for i := 0; i < b.N; i++ {
+                  b.StopTimer()
+                  tree := fillTree(alloc(), keys)
+                  b.StartTimer()
+                  for _, k := range keys {
+                        tree.Delete(k)
+                  }
+            }
+      })
+}
+
+// Cada iteración busca una llave del árbol.
+func BenchmarkFind(b *testing.B) {
+      runOrders(b, func(b *testing.B, alloc func() Allocator, keys []int) {
+            tree := fillTree(alloc(), keys)
+            b.ResetTimer()
+            for i := 0; i < b.N; i++ {
+                  tree.FindKey(keys[i%len(keys)])
+            }
+      })
+}
+
+// Cada iteración vacía un árbol lleno; el llenado no se mide.
+func BenchmarkClear(b *testing.B) {
+      for _, alloc := range benchAllocators {
+            for _, size := range benchSizes {
+                  keys := keyOrders[0].keys(size)
+                  b.Run(fmt.Sprintf("%s/%d", alloc.name, size), func(b *testing.B) {
+                        b.ReportAllocs()
+                        for i := 0; i < b.N; i++ {
+                              b.StopTimer()
+                              tree := fillTree(alloc.new(), keys)
+                              b.StartTimer()
+                              tree.Clear()
+                        }
+                  })
+            }
+      }
+}
+
+// Cada iteración recorre el árbol completo: con InorderIterator, que usa un
+// canal y una goroutine, con Each y con recursión directa sobre los nodos.
+func BenchmarkIterate(b *testing.B) {
+      var visit func(*Node)
+      visit = func(node *Node) {
+            if node != nil {
+                  visit(node.left)
+                  visit(node.right)
+            }
+      }
+      ways := []struct {
+            name string
+            walk func(*RBTree)
+      }{
+            {"InorderIterator", func(tree *RBTree) {
+                  iter := &InorderIterator{}
+                  for range iter.Iterate(tree.root) {
+                  }
+            }},
+            {"Each", func(tree *RBTree) {
+                  tree.Each(func(interface{}) bool { return true })
+            }},
+            {"Direct", func(tree *RBTree) {
+                  visit(tree.root)
+            }},
+      }
+
+      for _, way := range ways {
+            for _, size := range benchSizes {
+                  b.Run(fmt.Sprintf("%s/%d", way.name, size), func(b *testing.B) {
+                        tree := fillTree(nil, keyOrders[0].keys(size))
+                        b.ReportAllocs()
+                        b.ResetTimer()
+                        for i := 0; i < b.N; i++ {
+                              way.walk(tree)
+                        }
+                  })
+            }
+      }
+}
+
+// Cada iteración inserta todas las llaves en un árbol o en una estructura
+// alternativa: un CompactTree, un map que se ordena una vez al final (como
+// haría quien necesita recorrer las llaves en orden) y un arreglo que se
+// mantiene ordenado con búsqueda binaria.
+func BenchmarkInsertAlternatives(b *testing.B) {
+      structures := []struct {
+            name string
+            fill func(keys []int)
+      }{
+            {"RBTree", func(keys []int) {
+                  fillTree(nil, keys)
+            }},
+            {"CompactTree", func(keys []int) {
+                  tree := NewCompactTree(IntCmp)
+                  for _, k := range keys {
+                        tree.Insert(k)
+                  }
+            }},
+            {"MapSort", func(keys []int) {
+                  set := map[int]struct{}{}
+                  for _, k := range keys {
+                        set[k] = struct{}{}
+                  }
+                  sorted := make([]int, 0, len(set))
+                  for k := range set {
+                        sorted = append(sorted, k)
+                  }
+                  sort.Ints(sorted)
+            }},
+            {"SortedSlice", func(keys []int) {
+                  sorted := []int{}
+                  for _, k := range keys {
+                        pos := sort.SearchInts(sorted, k)
+                        if pos < len(sorted) && sorted[pos] == k {
+                              continue
+                        }
+                        sorted = append(sorted, 0)
+                        copy(sorted[pos+1:], sorted[pos:])
+                        sorted[pos] = k
+                  }
+            }},
+      }
+
+      for _, structure := range structures {
+            for _, size := range benchSizes {
+                  keys := keyOrders[0].keys(size)
+                  b.Run(fmt.Sprintf("%s/%d", structure.name, size), func(b *testing.B) {
+                        b.ReportAllocs()
+                        for i := 0; i < b.N; i++ {
+                              structure.fill(keys)
+                        }
+                  })
+            }
+      }
+}
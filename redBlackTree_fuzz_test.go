@@ -0,0 +1,163 @@
+package redBlackTree
+
+import (
+      "fmt"
+      "sort"
+      "strings"
+      "testing"
+)
+
+// Prueba el árbol contra un modelo de referencia con go test -fuzz=FuzzOps.
+// Cada entrada se interpreta como una secuencia de operaciones (insert,
+// delete, find, clear) que se aplican tanto a un RBTree como a un arreglo
+// ordenado, revisando después de cada paso que ambos tengan el mismo
+// contenido y que el árbol cumpla las condiciones de árbol rojinegro. Si
+// falla, el mensaje incluye la secuencia como script para rbtree -script.
+
+// Cada operación ocupa dos bytes: el primero indica la operación y el segundo
+// el valor. Los valores se limitan a fuzzMaxValue para que haya repeticiones
+// y borrados de valores que sí están en el árbol.
+const (
+      fuzzOpSize   = 2
+      fuzzMaxValue = 64
+)
+
+type fuzzOp struct {
+      kind  string
+      value int
+}
+
+func (o fuzzOp) String() string {
+      if o.kind == "clear" {
+            return o.kind
+      }
+      return fmt.Sprintf("%s %d", o.kind, o.value)
+}
+
+// decodeOps convierte los bytes en operaciones. clear es poco frecuente para
+// que los árboles lleguen a crecer; un byte sobrante al final se ignora.
+func decodeOps(data []byte) []fuzzOp {
+      ops := []fuzzOp{}
+      for i := 0; i+fuzzOpSize <= len(data); i += fuzzOpSize {
+            o := fuzzOp{value: int(data[i+1]) % fuzzMaxValue}
+            switch {
+            case data[i]%32 == 0:
+                  o.kind = "clear"
+            case data[i]%3 == 0:
+                  o.kind = "find"
+            case data[i]%3 == 1:
+                  o.kind = "insert"
+            default:
+                  o.kind = "delete"
+            }
+            ops = append(ops, o)
+      }
+      return ops
+}
+
+// El modelo de referencia es un arreglo ordenado sin repeticiones.
+type fuzzModel []int
+
+func (m fuzzModel) search(v int) (int, bool) {
+      i := sort.SearchInts(m, v)
+      return i, i < len(m) && m[i] == v
+}
+
+// checkOps aplica las operaciones al árbol y al modelo y devuelve un error en
+// la primera operación después de la cual difieren o el árbol no es válido.
+func checkOps(data []byte) (err error) {
+      tree := NewTree(IntCmp)
+      m := fuzzModel{}
+
+      step := 0
+      ops := decodeOps(data)
+      defer func() {
+            if r := recover(); r != nil {
+                  err = fmt.Errorf("paso %d (%v): pánico: %v", step+1, ops[step], r)
+            }
+      }()
+
+      for step = range ops {
+            o := ops[step]
+            i, found := m.search(o.value)
+            switch o.kind {
+            case "insert":
+                  if inserted := tree.Insert(o.value); inserted == found {
+                        return fmt.Errorf("paso %d (%v): Insert devolvió %v", step+1, o, inserted)
+                  }
+                  if !found {
+                        m = append(m[:i], append(fuzzModel{o.value}, m[i:]...)...)
+                  }
+            case "delete":
+                  tree.Delete(o.value)
+                  if found {
+                        m = append(m[:i], m[i+1:]...)
+                  }
+            case "find":
+                  if got := tree.FindKey(o.value); got != found {
+                        return fmt.Errorf("paso %d (%v): Find devolvió %v", step+1, o, got)
+                  }
+            case "clear":
+                  tree.Clear()
+                  m = m[:0]
+            }
+
+            if err := tree.Validate(); err != nil {
+                  return fmt.Errorf("paso %d (%v): %v", step+1, o, err)
+            }
+            if err := sameContents(tree, m); err != nil {
+                  return fmt.Errorf("paso %d (%v): %v", step+1, o, err)
+            }
+      }
+      return nil
+}
+
+// sameContents compara los valores del árbol en in-order con el modelo.
+func sameContents(tree *RBTree, m fuzzModel) error {
+      values := []int{}
+      tree.Each(func(v interface{}) bool {
+            values = append(values, v.(int))
+            return true
+      })
+      if len(values) != len(m) {
+            return fmt.Errorf("el árbol tiene %d valores y el modelo %d", len(values), len(m))
+      }
+      for i := range values {
+            if values[i] != m[i] {
+                  return fmt.Errorf("el árbol tiene %v y el modelo %v", values, m)
+            }
+      }
+      return nil
+}
+
+// script devuelve las operaciones como script para rbtree -script.
+func script(data []byte) string {
+      lines := []string{}
+      for _, o := range decodeOps(data) {
+            lines = append(lines, o.String())
+      }
+      return strings.Join(lines, "\n")
+}
+
+func FuzzOps(f *testing.F) {
+      // Inserciones crecientes y decrecientes, que fuerzan rotaciones hacia
+      // un mismo lado, seguidas de borrados en distinto orden.
+      ascending, descending := []byte{}, []byte{}
+      for v := byte(0); v < 32; v++ {
+            ascending = append(ascending, 1, v)
+            descending = append(descending, 1, 63-v)
+      }
+      for v := byte(0); v < 32; v += 2 {
+            ascending = append(ascending, 2, v)
+            descending = append(descending, 2, 31-v)
+      }
+      f.Add(ascending)
+      f.Add(descending)
+      f.Add([]byte{1, 5, 1, 3, 1, 8, 3, 3, 2, 5, 2, 9, 0, 0, 1, 5, 2, 5})
+
+      f.Fuzz(func(t *testing.T, data []byte) {
+            if err := checkOps(data); err != nil {
+                  t.Fatalf("%v\n# script para rbtree -script\n%s", err, script(data))
+            }
+      })
+}
@@ -0,0 +1,62 @@
+package redBlackTree
+
+import (
+      "bytes"
+      "log/slog"
+      "strings"
+      "testing"
+)
+
+// churn inserta y borra valores para pasar por los casos de borrado.
+func churn(tree *RBTree) {
+      for i := 0; i < 64; i++ {
+            tree.Insert(i)
+      }
+      for i := 0; i < 64; i += 2 {
+            tree.Delete(i)
+      }
+}
+
+// Sin SetLogger el árbol no registra nada, ni siquiera en el logger por
+// defecto de slog.
+func TestDebugSilentByDefault(t *testing.T) {
+      var buf bytes.Buffer
+      previous := slog.Default()
+      slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+      defer slog.SetDefault(previous)
+
+      churn(NewTree(IntCmp))
+      if buf.Len() != 0 {
+            t.Fatalf("se registró sin logger: %s", buf.String())
+      }
+}
+
+func TestDebugWritesToLogger(t *testing.T) {
+      var buf bytes.Buffer
+      tree := NewTree(IntCmp)
+      tree.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+      churn(tree)
+
+      out := buf.String()
+      for _, want := range []string{"level=DEBUG", `msg=borrado`, `msg="arreglo de borrado"`, `caso="dos hijos"`} {
+            if !strings.Contains(out, want) {
+                  t.Errorf("el registro no contiene %s", want)
+            }
+      }
+
+      // Con un handler de nivel Info los mensajes de depuración se descartan.
+      buf.Reset()
+      tree.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+      churn(tree)
+      if buf.Len() != 0 {
+            t.Fatalf("se registró en nivel Info: %s", buf.String())
+      }
+
+      // SetLogger(nil) deja de registrar.
+      tree.SetLogger(nil)
+      tree.Clear()
+      churn(tree)
+      if buf.Len() != 0 {
+            t.Fatalf("se registró después de SetLogger(nil): %s", buf.String())
+      }
+}
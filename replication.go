@@ -0,0 +1,230 @@
+/* Replicación de un árbol hacia réplicas de solo lectura.
+   El líder se suscribe a los cambios de su árbol y escribe por un io.Writer
+   un registro numerado por cada Insert, Delete, Replace y Clear. El seguidor
+   lee esos registros de un io.Reader y los aplica a su propio árbol.
+
+   Cada registro tiene el mismo formato que los de la bitácora del árbol
+   persistente (largo, CRC-32 y contenido); el contenido es el número de
+   secuencia (uint64), la operación y el valor codificado. Aplicar un
+   registro es idempotente: los que ya se aplicaron se ignoran. Si el
+   seguidor encuentra un salto en la secuencia (por ejemplo, porque se
+   conectó tarde o perdió registros) devuelve un *ErrBehind, y se pone al día
+   leyendo una instantánea del líder, que lleva el árbol completo y su número
+   de secuencia.
+
+   El líder escribe cada registro dentro de la operación que cambió el árbol,
+   así que un io.Writer que se bloquea (por ejemplo, un io.Pipe que nadie lee)
+   bloquea también al Insert o Delete que lo provocó. Para un seguidor lento
+   o remoto conviene dar al líder un io.Writer que no se bloquee: uno que
+   guarde los registros en memoria y los envíe aparte, o que devuelva un error
+   si se llena, con lo que el seguidor se pone al día con una instantánea.
+*/
+
+package redBlackTree
+
+import (
+      "bufio"
+      "bytes"
+      "encoding/binary"
+      "fmt"
+      "io"
+)
+
+// Operaciones de los registros de replicación.
+const (
+      replInsert byte = iota + 1
+      replDelete
+      replReplace
+      replClear
+      replSnapshot
+)
+
+// ErrBehind indica que el seguidor perdió registros: esperaba el número Want
+// y recibió Got. Debe ponerse al día con una instantánea del líder.
+type ErrBehind struct {
+      Want, Got uint64
+}
+
+func (e *ErrBehind) Error() string {
+      return fmt.Sprintf("faltan registros: se esperaba el %d y llegó el %d", e.Want, e.Got)
+}
+
+// appendReplRecord agrega a buf el registro de replicación con el número pSeq.
+func appendReplRecord(buf []byte, pSeq uint64, op byte, pValue interface{}) ([]byte, error) {
+      payload := binary.BigEndian.AppendUint64(nil, pSeq)
+      payload = append(payload, op)
+      if op != replClear {
+            var err error
+            if payload, err = appendValue(payload, pValue); err != nil {
+                  return buf, err
+            }
+      }
+      return appendFrame(buf, payload), nil
+}
+
+// El líder guarda el número del último registro y el primer error al
+// escribir, después del cual deja de escribir.
+type Leader struct {
+      tree        *RBTree
+      w           io.Writer
+      seq         uint64
+      err         error
+      unsubscribe func()
+}
+
+// NewLeader empieza a escribir en w los cambios de pTree. Todo cambio al
+// árbol, por cualquier medio (incluidos Txn y Undo), se replica. Cada cambio
+// espera a que w.Write termine, por lo que w no debe bloquearse.
+func NewLeader(pTree *RBTree, w io.Writer) *Leader {
+      leader := &Leader{tree: pTree, w: w}
+      leader.unsubscribe = pTree.Subscribe(leader.onEvent)
+      return leader
+}
+
+func (leader *Leader) onEvent(pEvent Event) {
+      if leader.err != nil {
+            return
+      }
+      var op byte
+      switch pEvent.Kind {
+      case Inserted:
+            op = replInsert
+      case Deleted:
+            op = replDelete
+      case Replaced:
+            op = replReplace
+      case Cleared:
+            op = replClear
+      default:
+            return
+      }
+
+      leader.seq++
+      record, err := appendReplRecord(nil, leader.seq, op, pEvent.Value)
+      if err == nil {
+            _, err = leader.w.Write(record)
+      }
+      leader.err = err
+}
+
+// Seq devuelve el número del último registro.
+func (leader *Leader) Seq() uint64 {
+      return leader.seq
+}
+
+// Err devuelve el primer error al escribir un registro, o nil. Después de un
+// error el líder deja de escribir y los seguidores deben ponerse al día con
+// una instantánea.
+func (leader *Leader) Err() error {
+      return leader.err
+}
+
+// Snapshot escribe en w una instantánea con el contenido del árbol y el
+// número del último registro.
+func (leader *Leader) Snapshot(w io.Writer) error {
+      payload := binary.BigEndian.AppendUint64(nil, leader.seq)
+      payload = append(payload, replSnapshot)
+      buf := bytes.NewBuffer(payload)
+      if _, err := leader.tree.WriteTo(buf); err != nil {
+            return err
+      }
+      _, err := w.Write(appendFrame(nil, buf.Bytes()))
+      return err
+}
+
+// Close deja de replicar los cambios del árbol.
+func (leader *Leader) Close() {
+      leader.unsubscribe()
+}
+
+// El seguidor guarda su árbol y el número del último registro aplicado.
+type Follower struct {
+      tree *RBTree
+      cmp  Cmp
+      seq  uint64
+}
+
+// Se define un nuevo seguidor con un árbol vacío ordenado con pCmp.
+func NewFollower(pCmp Cmp) *Follower {
+      return &Follower{tree: NewTree(pCmp), cmp: pCmp}
+}
+
+// Tree devuelve el árbol del seguidor, para consultarlo. No debe modificarse.
+func (follower *Follower) Tree() *RBTree {
+      return follower.tree
+}
+
+// Seq devuelve el número del último registro aplicado.
+func (follower *Follower) Seq() uint64 {
+      return follower.seq
+}
+
+// Follow aplica los registros (o instantáneas) que lee de r hasta el final,
+// y entonces devuelve nil. Devuelve un *ErrBehind si encuentra un salto en
+// la secuencia, o un error si un registro está dañado.
+func (follower *Follower) Follow(r io.Reader) error {
+      br := bufio.NewReader(r)
+      for {
+            payload, err := readFrame(br)
+            if err == io.EOF {
+                  return nil
+            }
+            if err != nil {
+                  return err
+            }
+            if err := follower.apply(payload); err != nil {
+                  return err
+            }
+      }
+}
+
+// apply aplica un registro si es el siguiente de la secuencia.
+func (follower *Follower) apply(payload []byte) error {
+      if len(payload) < 9 {
+            return fmt.Errorf("registro de replicación muy corto")
+      }
+      seq, op := binary.BigEndian.Uint64(payload), payload[8]
+
+      switch {
+      case op == replSnapshot:
+            if seq < follower.seq {
+                  return nil
+            }
+            tree, err := ReadTree(bytes.NewReader(payload[9:]), follower.cmp)
+            if err != nil {
+                  return fmt.Errorf("instantánea inválida: %v", err)
+            }
+            // Se copia el contenido al mismo árbol, para que sigan siendo
+            // válidos el árbol que devolvió Tree y sus suscripciones.
+            follower.tree.Clear()
+            for _, value := range tree.contents() {
+                  follower.tree.Insert(value)
+            }
+            follower.seq = seq
+            return nil
+      case seq <= follower.seq:
+            // Ya se aplicó.
+            return nil
+      case seq > follower.seq+1:
+            return &ErrBehind{Want: follower.seq + 1, Got: seq}
+      case op == replClear:
+            follower.tree.Clear()
+      default:
+            value, _, err := decodeValue(payload[9:], false)
+            if err != nil {
+                  return err
+            }
+            switch op {
+            case replInsert, replReplace:
+                  if !follower.tree.Insert(value) {
+                        follower.tree.Replace(value)
+                  }
+            case replDelete:
+                  follower.tree.Delete(value)
+            default:
+                  return fmt.Errorf("operación desconocida %d en la replicación", op)
+            }
+      }
+      follower.seq = seq
+      return nil
+}
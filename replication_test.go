@@ -0,0 +1,204 @@
+package redBlackTree
+
+import (
+      "bytes"
+      "errors"
+      "io"
+      "reflect"
+      "testing"
+)
+
+// recordWriter guarda por separado cada registro que escribe el líder.
+type recordWriter struct {
+      records [][]byte
+}
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+      w.records = append(w.records, append([]byte{}, p...))
+      return len(p), nil
+}
+
+func (w *recordWriter) from(i int) io.Reader {
+      return bytes.NewReader(bytes.Join(w.records[i:], nil))
+}
+
+// Cambios de prueba: inserta, borra, reemplaza y vacía el árbol.
+func changeReplicated(tree *RBTree) {
+      for i := 0; i < 20; i++ {
+            tree.Insert(i)
+      }
+      tree.Delete(3)
+      tree.Replace(4)
+      tree.Clear()
+      for i := 10; i < 30; i += 2 {
+            tree.Insert(i)
+      }
+      tree.Delete(12)
+}
+
+func checkReplica(t *testing.T, pLeader *Leader, pFollower *Follower) {
+      t.Helper()
+      if err := pFollower.Tree().Validate(); err != nil {
+            t.Fatal(err)
+      }
+      want, got := pLeader.tree.contents(), pFollower.Tree().contents()
+      if !reflect.DeepEqual(got, want) {
+            t.Fatalf("el seguidor tiene %v, el líder %v", got, want)
+      }
+      if pFollower.Seq() != pLeader.Seq() {
+            t.Fatalf("el seguidor va en el registro %d, el líder en el %d", pFollower.Seq(), pLeader.Seq())
+      }
+}
+
+func TestReplicationOverPipe(t *testing.T) {
+      r, w := io.Pipe()
+      tree := NewTree(IntCmp)
+      leader := NewLeader(tree, w)
+      follower := NewFollower(IntCmp)
+
+      done := make(chan error)
+      go func() {
+            done <- follower.Follow(r)
+      }()
+      changeReplicated(tree)
+      leader.Close()
+      w.Close()
+
+      if err := <-done; err != nil {
+            t.Fatal(err)
+      }
+      if err := leader.Err(); err != nil {
+            t.Fatal(err)
+      }
+      checkReplica(t, leader, follower)
+}
+
+// Recibir registros que ya se aplicaron no cambia nada.
+func TestReplicationIdempotent(t *testing.T) {
+      var w recordWriter
+      tree := NewTree(IntCmp)
+      leader := NewLeader(tree, &w)
+      changeReplicated(tree)
+
+      follower := NewFollower(IntCmp)
+      for _, start := range []int{0, 0, 5, len(w.records) - 1} {
+            if err := follower.Follow(w.from(start)); err != nil {
+                  t.Fatalf("desde el registro %d: %v", start, err)
+            }
+            checkReplica(t, leader, follower)
+      }
+}
+
+// Un seguidor que perdió registros recibe un *ErrBehind y se pone al día
+// con una instantánea.
+func TestReplicationResync(t *testing.T) {
+      var w recordWriter
+      tree := NewTree(IntCmp)
+      leader := NewLeader(tree, &w)
+      changeReplicated(tree)
+
+      follower := NewFollower(IntCmp)
+      if err := follower.Follow(w.from(0)); err != nil {
+            t.Fatal(err)
+      }
+
+      // El seguidor se desconecta y pierde los registros siguientes.
+      lost := len(w.records)
+      for i := 100; i < 110; i++ {
+            tree.Insert(i)
+      }
+      tree.Delete(100)
+      var behind *ErrBehind
+      err := follower.Follow(w.from(lost + 3))
+      if !errors.As(err, &behind) {
+            t.Fatalf("Follow devolvió %v, se esperaba un *ErrBehind", err)
+      }
+      if behind.Want != uint64(lost)+1 || behind.Got != uint64(lost)+4 {
+            t.Fatalf("ErrBehind = %+v", behind)
+      }
+
+      var snapshot bytes.Buffer
+      if err := leader.Snapshot(&snapshot); err != nil {
+            t.Fatal(err)
+      }
+      subscribed := follower.Tree()
+      if err := follower.Follow(&snapshot); err != nil {
+            t.Fatal(err)
+      }
+      if follower.Tree() != subscribed {
+            t.Fatal("la instantánea cambió el árbol del seguidor")
+      }
+      checkReplica(t, leader, follower)
+
+      // Los registros siguientes a la instantánea se aplican normalmente, y
+      // los anteriores se ignoran.
+      next := len(w.records)
+      tree.Insert(200)
+      tree.Delete(102)
+      if err := follower.Follow(w.from(lost)); err != nil {
+            t.Fatal(err)
+      }
+      checkReplica(t, leader, follower)
+      if err := follower.Follow(w.from(next)); err != nil {
+            t.Fatal(err)
+      }
+      checkReplica(t, leader, follower)
+}
+
+// Una instantánea más antigua que el seguidor se ignora.
+func TestReplicationOldSnapshot(t *testing.T) {
+      var w recordWriter
+      tree := NewTree(IntCmp)
+      leader := NewLeader(tree, &w)
+      tree.Insert(1)
+
+      var snapshot bytes.Buffer
+      if err := leader.Snapshot(&snapshot); err != nil {
+            t.Fatal(err)
+      }
+      tree.Insert(2)
+
+      follower := NewFollower(IntCmp)
+      if err := follower.Follow(w.from(0)); err != nil {
+            t.Fatal(err)
+      }
+      if err := follower.Follow(&snapshot); err != nil {
+            t.Fatal(err)
+      }
+      checkReplica(t, leader, follower)
+}
+
+// Un registro dañado se informa como error.
+func TestReplicationCorruptRecord(t *testing.T) {
+      var w recordWriter
+      tree := NewTree(IntCmp)
+      NewLeader(tree, &w)
+      tree.Insert(1)
+
+      w.records[0][len(w.records[0])-1] ^= 0x01
+      var behind *ErrBehind
+      if err := NewFollower(IntCmp).Follow(w.from(0)); err == nil || errors.As(err, &behind) {
+            t.Fatalf("Follow devolvió %v con un registro dañado", err)
+      }
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+      return 0, errors.New("sin conexión")
+}
+
+// Después de un error al escribir, el líder deja de escribir pero el árbol
+// sigue cambiando.
+func TestLeaderStopsAfterWriteError(t *testing.T) {
+      tree := NewTree(IntCmp)
+      leader := NewLeader(tree, failingWriter{})
+      tree.Insert(1)
+      tree.Insert(2)
+      if leader.Err() == nil {
+            t.Fatal("Err no informa el error al escribir")
+      }
+      if tree.Len() != 2 {
+            t.Fatalf("Len() = %d", tree.Len())
+      }
+}
@@ -0,0 +1,139 @@
+/* Operaciones que devuelven errores en lugar de entrar en pánico.
+   IntCmp, StringCmp y la mayoría de comparadores hacen aserciones de tipo sin
+   revisar, así que un valor del tipo equivocado hace que el árbol entre en
+   pánico a media búsqueda. Las operaciones Try recuperan esos pánicos y los
+   devuelven como *ErrIncomparable. Como todas las comparaciones ocurren antes
+   de modificar el árbol, este queda igual que antes de la operación fallida.
+*/
+
+package redBlackTree
+
+import (
+      "fmt"
+      "reflect"
+)
+
+// ErrIncomparable indica que el comparador no pudo comparar dos valores.
+// Left y Right son los tipos de los operandos, en el orden en que se pasaron
+// al comparador, y Cause el valor con que entró en pánico.
+type ErrIncomparable struct {
+      Left, Right reflect.Type
+      Cause       interface{}
+}
+
+func (e *ErrIncomparable) Error() string {
+      return fmt.Sprintf("no se puede comparar %v con %v: %v", e.Left, e.Right, e.Cause)
+}
+
+// ErrWrongType indica que se usó un valor de un tipo distinto al del árbol,
+// en un árbol creado con NewTreeOf.
+type ErrWrongType struct {
+      Want, Got reflect.Type
+}
+
+func (e *ErrWrongType) Error() string {
+      return fmt.Sprintf("el árbol guarda valores de tipo %v, no %v", e.Want, e.Got)
+}
+
+// Se define un nuevo árbol que solamente acepta valores del tipo pType (o que
+// lo implementen, si es una interfaz). Insert, Find y Delete entran en pánico
+// con un *ErrWrongType ante otro tipo, antes de llamar al comparador, y las
+// operaciones Try lo devuelven como error.
+func NewTreeOf(pType reflect.Type, pCmp Cmp) *RBTree {
+      tree := NewTree(pCmp)
+      tree.valueType = pType
+      return tree
+}
+
+// checkType revisa que pValue sea del tipo del árbol, si este tiene uno.
+func (tree *RBTree) checkType(pValue interface{}) error {
+      if tree.valueType == nil {
+            return nil
+      }
+      valueType := reflect.TypeOf(pValue)
+      if valueType == nil {
+            // Solamente una interfaz puede guardar nil.
+            if tree.valueType.Kind() == reflect.Interface {
+                  return nil
+            }
+      } else if valueType.AssignableTo(tree.valueType) {
+            return nil
+      }
+      return &ErrWrongType{Want: tree.valueType, Got: valueType}
+}
+
+// mustCheckType entra en pánico con el error de checkType, si lo hay.
+func (tree *RBTree) mustCheckType(pValue interface{}) {
+      if err := tree.checkType(pValue); err != nil {
+            panic(err)
+      }
+}
+
+// try ejecuta op con un comparador que envuelve al del árbol y convierte sus
+// pánicos en *ErrIncomparable con los tipos de los operandos, y devuelve ese
+// error (o el de tipo) en lugar de entrar en pánico. Antes de op se compara
+// pValue consigo mismo, para rechazarlo aunque el árbol esté vacío y op no
+// llegue a compararlo: si no, un valor incomparable quedaría insertado y
+// haría fallar todas las operaciones siguientes. El comparador se pasa a op
+// en lugar de cambiar el del árbol, para que try no modifique el árbol y
+// pueda usarse junto con otras consultas. Los pánicos que no vienen del
+// comparador se propagan igual.
+func (tree *RBTree) try(pValue interface{}, op func(Cmp)) (err error) {
+      if err := tree.checkType(pValue); err != nil {
+            return err
+      }
+
+      cmp := tree.cmp
+      guarded := func(o1, o2 interface{}) int {
+            defer func() {
+                  if r := recover(); r != nil {
+                        panic(&ErrIncomparable{Left: reflect.TypeOf(o1), Right: reflect.TypeOf(o2), Cause: r})
+                  }
+            }()
+            return cmp(o1, o2)
+      }
+      defer func() {
+            if r := recover(); r != nil {
+                  incomparable, ok := r.(*ErrIncomparable)
+                  if !ok {
+                        panic(r)
+                  }
+                  err = incomparable
+            }
+      }()
+
+      guarded(pValue, pValue)
+      op(guarded)
+      return nil
+}
+
+// TryInsert inserta pValue como Insert, pero devuelve un error si el valor no
+// es del tipo del árbol o si el comparador no puede compararlo.
+func (tree *RBTree) TryInsert(pValue interface{}) (bool, error) {
+      inserted := false
+      err := tree.try(pValue, func(pCmp Cmp) {
+            inserted = tree.insert(pValue, pCmp)
+      })
+      return inserted, err
+}
+
+// TryFind busca pKey como Find, pero devuelve un error si el valor no es del
+// tipo del árbol o si el comparador no puede compararlo.
+func (tree *RBTree) TryFind(pKey interface{}) (bool, *Node, error) {
+      var node *Node
+      err := tree.try(pKey, func(pCmp Cmp) {
+            node = tree.lookupWith(pKey, pCmp)
+      })
+      return node != nil, node, err
+}
+
+// TryDelete borra pKey como Delete y devuelve si estaba en el árbol, o un
+// error si el valor no es del tipo del árbol o si el comparador no puede
+// compararlo.
+func (tree *RBTree) TryDelete(pKey interface{}) (bool, error) {
+      found := false
+      err := tree.try(pKey, func(pCmp Cmp) {
+            found = tree.remove(pKey, pCmp)
+      })
+      return found, err
+}
@@ -0,0 +1,84 @@
+package redBlackTree
+
+import (
+      "errors"
+      "reflect"
+      "sync"
+      "testing"
+)
+
+func TestTryInsertRejectsIncomparableOnEmptyTree(t *testing.T) {
+      tree := NewTree(IntCmp)
+
+      inserted, err := tree.TryInsert("x")
+      var incomparable *ErrIncomparable
+      if inserted || !errors.As(err, &incomparable) {
+            t.Fatalf("TryInsert(\"x\") = %v, %v; se esperaba false y *ErrIncomparable", inserted, err)
+      }
+      if tree.Len() != 0 {
+            t.Fatalf("Len() = %d después de rechazar el valor", tree.Len())
+      }
+
+      // El árbol no quedó dañado por el valor rechazado.
+      if inserted, err := tree.TryInsert(3); !inserted || err != nil {
+            t.Fatalf("TryInsert(3) = %v, %v", inserted, err)
+      }
+}
+
+func TestTryOperationsReportTypes(t *testing.T) {
+      tree := NewTree(IntCmp)
+      tree.Insert(1)
+
+      tests := []struct {
+            name string
+            op   func() error
+      }{
+            {"TryInsert", func() error { _, err := tree.TryInsert("a"); return err }},
+            {"TryFind", func() error { _, _, err := tree.TryFind("a"); return err }},
+            {"TryDelete", func() error { _, err := tree.TryDelete("a"); return err }},
+      }
+      for _, test := range tests {
+            var incomparable *ErrIncomparable
+            if err := test.op(); !errors.As(err, &incomparable) {
+                  t.Errorf("%s: error %v, se esperaba *ErrIncomparable", test.name, err)
+            } else if incomparable.Left != reflect.TypeOf("") {
+                  t.Errorf("%s: Left = %v", test.name, incomparable.Left)
+            }
+      }
+      if err := tree.Validate(); err != nil || tree.Len() != 1 {
+            t.Fatalf("el árbol cambió: Len() = %d, Validate() = %v", tree.Len(), err)
+      }
+}
+
+func TestTryWrongType(t *testing.T) {
+      tree := NewTreeOf(reflect.TypeOf(0), IntCmp)
+      var wrongType *ErrWrongType
+      if _, err := tree.TryInsert("a"); !errors.As(err, &wrongType) {
+            t.Fatalf("TryInsert(\"a\") = %v, se esperaba *ErrWrongType", err)
+      }
+}
+
+// TryFind no modifica el árbol, así que puede usarse a la vez desde varios
+// lectores (se revisa con -race).
+func TestTryFindConcurrentReaders(t *testing.T) {
+      tree := NewConcurrentTree(NewTree(IntCmp))
+      for i := 0; i < 100; i++ {
+            tree.Insert(i)
+      }
+
+      var wg sync.WaitGroup
+      for g := 0; g < 8; g++ {
+            wg.Add(1)
+            go func() {
+                  defer wg.Done()
+                  for i := 0; i < 200; i++ {
+                        tree.View(func(tree *RBTree) {
+                              if found, _, err := tree.TryFind(i % 150); err != nil || found != (i%150 < 100) {
+                                    t.Errorf("TryFind(%d) = %v, %v", i%150, found, err)
+                              }
+                        })
+                  }
+            }()
+      }
+      wg.Wait()
+}
@@ -0,0 +1,80 @@
+/* Serialización binaria del árbol.
+   Se escriben los valores en in-order, precedidos por un encabezado con la
+   cantidad de valores. La forma del árbol no se guarda: al leerlo se vuelve
+   a construir con el comparador que se indique.
+*/
+
+package redBlackTree
+
+import (
+      "bufio"
+      "encoding/binary"
+      "fmt"
+      "io"
+)
+
+// Los primeros bytes de un árbol serializado.
+const serialMagic = "RBT1"
+
+// WriteTo escribe los valores del árbol en w y devuelve la cantidad de bytes
+// escritos. Devuelve un *ErrUnsupportedValue si algún valor no es de un tipo
+// que pueda codificarse.
+func (tree *RBTree) WriteTo(w io.Writer) (int64, error) {
+      bw := bufio.NewWriter(w)
+      written := int64(0)
+      write := func(b []byte) error {
+            n, err := bw.Write(b)
+            written += int64(n)
+            return err
+      }
+
+      buf := binary.AppendUvarint([]byte(serialMagic), uint64(tree.count))
+      if err := write(buf); err != nil {
+            return written, err
+      }
+
+      iter := &InorderIterator{}
+      ch := iter.Iterate(tree.root)
+      for node := range ch {
+            var err error
+            if buf, err = appendValue(buf[:0], node.value); err == nil {
+                  err = write(buf)
+            }
+            if err != nil {
+                  // Se vacía el canal para que la goroutine del iterador termine.
+                  for range ch {
+                  }
+                  return written, err
+            }
+      }
+      return written, bw.Flush()
+}
+
+// ReadTree lee un árbol escrito con WriteTo y lo construye con pCmp.
+func ReadTree(r io.Reader, pCmp Cmp) (*RBTree, error) {
+      br := bufio.NewReader(r)
+
+      magic := make([]byte, len(serialMagic))
+      if _, err := io.ReadFull(br, magic); err != nil {
+            return nil, unexpectedEOF(err)
+      }
+      if string(magic) != serialMagic {
+            return nil, fmt.Errorf("no es un árbol serializado")
+      }
+      count, err := binary.ReadUvarint(br)
+      if err != nil {
+            return nil, unexpectedEOF(err)
+      }
+
+      tree := NewTree(pCmp)
+      for i := uint64(0); i < count; i++ {
+            value, err := readValue(br)
+            if err != nil {
+                  return nil, unexpectedEOF(err)
+            }
+            if !tree.Insert(value) {
+                  return nil, fmt.Errorf("valor repetido %v", value)
+            }
+      }
+      return tree, nil
+}
@@ -0,0 +1,358 @@
+/* Servicio HTTP con JSON para una colección de árboles con nombre.
+   Cada árbol guarda enteros o hileras y se maneja mediante un
+   ConcurrentTree, así que el servicio puede atender varias peticiones a la
+   vez. Las rutas son:
+
+      GET    /trees                        nombres, tipos y tamaños
+      PUT    /trees/{name}?type=int|string crea un árbol
+      DELETE /trees/{name}                 borra un árbol
+      POST   /trees/{name}/values          inserta {"values": [...]}
+      GET    /trees/{name}/values/{value}  busca un valor
+      DELETE /trees/{name}/values/{value}  borra un valor
+      GET    /trees/{name}/range?low=&high=  valores en [low, high]
+      GET    /trees/{name}/min, /max       valor más pequeño o más grande
+      GET    /trees/{name}/rank/{value}    cantidad de valores menores
+      GET    /trees/{name}/select/{index}  valor en la posición index
+      GET    /trees/{name}/stats           medidas de la forma del árbol
+      GET    /trees/{name}/dot             el árbol en formato DOT
+
+   Los errores se responden como {"error": "..."} con el código adecuado.
+   Los cuerpos de más de MaxBodySize bytes se rechazan con 413.
+*/
+
+package server
+
+import (
+      "encoding/json"
+      "errors"
+      "fmt"
+      "net/http"
+      "sort"
+      "strconv"
+      "sync"
+
+      redBlackTree "github.com/luahir/Tarea-1-LP"
+)
+
+// Tamaño máximo, en bytes, del cuerpo de una petición.
+const MaxBodySize = 1 << 20
+
+// Un árbol de la colección, con el tipo de sus valores ("int" o "string").
+type namedTree struct {
+      kind string
+      tree *redBlackTree.ConcurrentTree
+}
+
+// parse convierte un valor recibido como texto (en la ruta o la consulta)
+// al tipo del árbol.
+func (t *namedTree) parse(pText string) (interface{}, error) {
+      if t.kind == "string" {
+            return pText, nil
+      }
+      n, err := strconv.Atoi(pText)
+      if err != nil {
+            return nil, fmt.Errorf("%q no es un entero", pText)
+      }
+      return n, nil
+}
+
+// decode convierte un valor recibido en JSON al tipo del árbol.
+func (t *namedTree) decode(pRaw json.RawMessage) (interface{}, error) {
+      if t.kind == "string" {
+            var s string
+            if err := json.Unmarshal(pRaw, &s); err != nil {
+                  return nil, fmt.Errorf("%s no es una hilera", pRaw)
+            }
+            return s, nil
+      }
+      var n int
+      if err := json.Unmarshal(pRaw, &n); err != nil {
+            return nil, fmt.Errorf("%s no es un entero", pRaw)
+      }
+      return n, nil
+}
+
+// El servidor guarda los árboles por nombre. Implementa http.Handler.
+type Server struct {
+      mu    sync.RWMutex
+      trees map[string]*namedTree
+      mux   *http.ServeMux
+}
+
+// Se define un nuevo servidor sin árboles.
+func New() *Server {
+      s := &Server{trees: map[string]*namedTree{}, mux: http.NewServeMux()}
+      s.mux.HandleFunc("GET /trees", s.listTrees)
+      s.mux.HandleFunc("PUT /trees/{name}", s.createTree)
+      s.mux.HandleFunc("DELETE /trees/{name}", s.dropTree)
+      s.mux.HandleFunc("POST /trees/{name}/values", s.withTree(s.insert))
+      s.mux.HandleFunc("GET /trees/{name}/values/{value}", s.withTree(s.get))
+      s.mux.HandleFunc("DELETE /trees/{name}/values/{value}", s.withTree(s.delete))
+      s.mux.HandleFunc("GET /trees/{name}/range", s.withTree(s.valueRange))
+      s.mux.HandleFunc("GET /trees/{name}/min", s.withTree(s.min))
+      s.mux.HandleFunc("GET /trees/{name}/max", s.withTree(s.max))
+      s.mux.HandleFunc("GET /trees/{name}/rank/{value}", s.withTree(s.rank))
+      s.mux.HandleFunc("GET /trees/{name}/select/{index}", s.withTree(s.selectIndex))
+      s.mux.HandleFunc("GET /trees/{name}/stats", s.withTree(s.stats))
+      s.mux.HandleFunc("GET /trees/{name}/dot", s.withTree(s.dot))
+      return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+      s.mux.ServeHTTP(w, r)
+}
+
+// Create crea el árbol pName con valores de tipo pKind ("int" o "string").
+func (s *Server) Create(pName, pKind string) error {
+      var cmp redBlackTree.Cmp
+      switch pKind {
+      case "int":
+            cmp = redBlackTree.IntCmp
+      case "string":
+            cmp = redBlackTree.StringCmp
+      default:
+            return fmt.Errorf("tipo desconocido %q (se espera int o string)", pKind)
+      }
+
+      s.mu.Lock()
+      defer s.mu.Unlock()
+      if _, ok := s.trees[pName]; ok {
+            return fmt.Errorf("ya existe el árbol %q", pName)
+      }
+      s.trees[pName] = &namedTree{
+            kind: pKind,
+            tree: redBlackTree.NewConcurrentTree(redBlackTree.NewOrderStatisticTree(cmp)),
+      }
+      return nil
+}
+
+// writeJSON responde con pBody en JSON y el código pStatus.
+func writeJSON(w http.ResponseWriter, pStatus int, pBody interface{}) {
+      w.Header().Set("Content-Type", "application/json")
+      w.WriteHeader(pStatus)
+      json.NewEncoder(w).Encode(pBody)
+}
+
+// writeError responde con el error en JSON y el código pStatus.
+func writeError(w http.ResponseWriter, pStatus int, err error) {
+      writeJSON(w, pStatus, map[string]string{"error": err.Error()})
+}
+
+// withTree busca el árbol de la ruta y se lo pasa a handler, o responde 404.
+func (s *Server) withTree(handler func(http.ResponseWriter, *http.Request, *namedTree)) http.HandlerFunc {
+      return func(w http.ResponseWriter, r *http.Request) {
+            name := r.PathValue("name")
+            s.mu.RLock()
+            t, ok := s.trees[name]
+            s.mu.RUnlock()
+            if !ok {
+                  writeError(w, http.StatusNotFound, fmt.Errorf("no existe el árbol %q", name))
+                  return
+            }
+            handler(w, r, t)
+      }
+}
+
+// Resumen de un árbol en la lista de árboles.
+type treeInfo struct {
+      Name string `json:"name"`
+      Type string `json:"type"`
+      Len  int    `json:"len"`
+}
+
+func (s *Server) listTrees(w http.ResponseWriter, r *http.Request) {
+      s.mu.RLock()
+      infos := make([]treeInfo, 0, len(s.trees))
+      for name, t := range s.trees {
+            infos = append(infos, treeInfo{Name: name, Type: t.kind, Len: t.tree.Len()})
+      }
+      s.mu.RUnlock()
+      sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+      writeJSON(w, http.StatusOK, map[string]interface{}{"trees": infos})
+}
+
+func (s *Server) createTree(w http.ResponseWriter, r *http.Request) {
+      kind := r.URL.Query().Get("type")
+      if kind == "" {
+            kind = "int"
+      }
+      if err := s.Create(r.PathValue("name"), kind); err != nil {
+            status := http.StatusConflict
+            if kind != "int" && kind != "string" {
+                  status = http.StatusBadRequest
+            }
+            writeError(w, status, err)
+            return
+      }
+      writeJSON(w, http.StatusCreated, treeInfo{Name: r.PathValue("name"), Type: kind})
+}
+
+func (s *Server) dropTree(w http.ResponseWriter, r *http.Request) {
+      name := r.PathValue("name")
+      s.mu.Lock()
+      _, ok := s.trees[name]
+      delete(s.trees, name)
+      s.mu.Unlock()
+      if !ok {
+            writeError(w, http.StatusNotFound, fmt.Errorf("no existe el árbol %q", name))
+            return
+      }
+      w.WriteHeader(http.StatusNoContent)
+}
+
+// Cuerpo de la petición de inserción.
+type insertRequest struct {
+      Values []json.RawMessage `json:"values"`
+}
+
+func (s *Server) insert(w http.ResponseWriter, r *http.Request, t *namedTree) {
+      var req insertRequest
+      body := http.MaxBytesReader(w, r.Body, MaxBodySize)
+      if err := json.NewDecoder(body).Decode(&req); err != nil {
+            var tooLarge *http.MaxBytesError
+            if errors.As(err, &tooLarge) {
+                  writeError(w, http.StatusRequestEntityTooLarge,
+                        fmt.Errorf("el cuerpo supera los %d bytes", MaxBodySize))
+                  return
+            }
+            writeError(w, http.StatusBadRequest, fmt.Errorf("cuerpo inválido: %v", err))
+            return
+      }
+      // Se revisan todos los valores antes de insertar, para no insertar
+      // solamente una parte.
+      values := make([]interface{}, len(req.Values))
+      for i, raw := range req.Values {
+            value, err := t.decode(raw)
+            if err != nil {
+                  writeError(w, http.StatusBadRequest, err)
+                  return
+            }
+            values[i] = value
+      }
+
+      inserted := 0
+      t.tree.Update(func(tree *redBlackTree.RBTree) {
+            for _, value := range values {
+                  if tree.Insert(value) {
+                        inserted++
+                  }
+            }
+      })
+      writeJSON(w, http.StatusOK, map[string]int{"inserted": inserted, "len": t.tree.Len()})
+}
+
+// parseValue convierte el valor de la ruta, o responde 400.
+func parseValue(w http.ResponseWriter, r *http.Request, t *namedTree) (interface{}, bool) {
+      value, err := t.parse(r.PathValue("value"))
+      if err != nil {
+            writeError(w, http.StatusBadRequest, err)
+            return nil, false
+      }
+      return value, true
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, t *namedTree) {
+      key, ok := parseValue(w, r, t)
+      if !ok {
+            return
+      }
+      found, value := t.tree.Find(key)
+      if !found {
+            writeError(w, http.StatusNotFound, fmt.Errorf("no existe el valor %v", key))
+            return
+      }
+      writeJSON(w, http.StatusOK, map[string]interface{}{"value": value})
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, t *namedTree) {
+      key, ok := parseValue(w, r, t)
+      if !ok {
+            return
+      }
+      writeJSON(w, http.StatusOK, map[string]bool{"deleted": t.tree.Delete(key)})
+}
+
+func (s *Server) valueRange(w http.ResponseWriter, r *http.Request, t *namedTree) {
+      query := r.URL.Query()
+      low, err := t.parse(query.Get("low"))
+      if err == nil {
+            var high interface{}
+            if high, err = t.parse(query.Get("high")); err == nil {
+                  writeJSON(w, http.StatusOK, map[string]interface{}{"values": t.tree.Range(low, high)})
+                  return
+            }
+      }
+      writeError(w, http.StatusBadRequest, err)
+}
+
+// writeValue responde con el valor, o 404 si no existe.
+func writeValue(w http.ResponseWriter, pValue interface{}, pFound bool) {
+      if !pFound {
+            writeError(w, http.StatusNotFound, fmt.Errorf("no existe el valor"))
+            return
+      }
+      writeJSON(w, http.StatusOK, map[string]interface{}{"value": pValue})
+}
+
+func (s *Server) min(w http.ResponseWriter, r *http.Request, t *namedTree) {
+      value, found := t.tree.Min()
+      writeValue(w, value, found)
+}
+
+func (s *Server) max(w http.ResponseWriter, r *http.Request, t *namedTree) {
+      value, found := t.tree.Max()
+      writeValue(w, value, found)
+}
+
+func (s *Server) rank(w http.ResponseWriter, r *http.Request, t *namedTree) {
+      key, ok := parseValue(w, r, t)
+      if !ok {
+            return
+      }
+      writeJSON(w, http.StatusOK, map[string]int{"rank": t.tree.Rank(key)})
+}
+
+func (s *Server) selectIndex(w http.ResponseWriter, r *http.Request, t *namedTree) {
+      index, err := strconv.Atoi(r.PathValue("index"))
+      if err != nil {
+            writeError(w, http.StatusBadRequest, fmt.Errorf("%q no es un índice", r.PathValue("index")))
+            return
+      }
+      value, found := t.tree.Select(index)
+      writeValue(w, value, found)
+}
+
+// Medidas de un árbol como se responden en /stats.
+type treeStats struct {
+      Len          int     `json:"len"`
+      Height       int     `json:"height"`
+      BlackHeight  int     `json:"black_height"`
+      Red          int     `json:"red"`
+      Black        int     `json:"black"`
+      MinDepth     int     `json:"min_depth"`
+      MaxDepth     int     `json:"max_depth"`
+      AverageDepth float64 `json:"average_depth"`
+}
+
+func (s *Server) stats(w http.ResponseWriter, r *http.Request, t *namedTree) {
+      var result treeStats
+      t.tree.View(func(tree *redBlackTree.RBTree) {
+            stats := tree.Stats()
+            result = treeStats{
+                  Len:          tree.Len(),
+                  Height:       tree.Height(),
+                  BlackHeight:  tree.BlackHeight(),
+                  Red:          stats.Red,
+                  Black:        stats.Black,
+                  MinDepth:     stats.MinDepth,
+                  MaxDepth:     stats.MaxDepth,
+                  AverageDepth: stats.AverageDepth,
+            }
+      })
+      writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) dot(w http.ResponseWriter, r *http.Request, t *namedTree) {
+      w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+      fmt.Fprint(w, t.tree.Dot())
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+      "encoding/json"
+      "fmt"
+      "net/http"
+      "net/http/httptest"
+      "reflect"
+      "strings"
+      "sync"
+      "testing"
+)
+
+// do envía la petición al servidor y devuelve el código y el cuerpo.
+func do(s *Server, pMethod, pPath, pBody string) (int, string) {
+      w := httptest.NewRecorder()
+      s.ServeHTTP(w, httptest.NewRequest(pMethod, pPath, strings.NewReader(pBody)))
+      return w.Code, w.Body.String()
+}
+
+// Cada caso se aplica en orden sobre el mismo servidor. Si want no está
+// vacío, el cuerpo JSON de la respuesta debe ser igual a want.
+func TestRoutes(t *testing.T) {
+      s := New()
+      tests := []struct {
+            method, path, body string
+            status             int
+            want               string
+      }{
+            {"GET", "/trees", "", 200, `{"trees":[]}`},
+            {"PUT", "/trees/nums", "", 201, `{"name":"nums","type":"int","len":0}`},
+            {"PUT", "/trees/words?type=string", "", 201, `{"name":"words","type":"string","len":0}`},
+            {"PUT", "/trees/nums", "", 409, ""},
+            {"PUT", "/trees/other?type=float", "", 400, ""},
+            {"POST", "/trees/nums/values", `{"values": [5, 1, 9, 3, 7, 5]}`, 200, `{"inserted":5,"len":5}`},
+            {"POST", "/trees/nums/values", `{"values": [2, "x"]}`, 400, ""},
+            {"POST", "/trees/nums/values", `{"values": `, 400, ""},
+            {"POST", "/trees/words/values", `{"values": ["b", "a"]}`, 200, `{"inserted":2,"len":2}`},
+            {"POST", "/trees/words/values", `{"values": [1]}`, 400, ""},
+            {"POST", "/trees/none/values", `{"values": [1]}`, 404, ""},
+            {"GET", "/trees", "", 200, `{"trees":[{"name":"nums","type":"int","len":5},{"name":"words","type":"string","len":2}]}`},
+            {"GET", "/trees/nums/values/3", "", 200, `{"value":3}`},
+            {"GET", "/trees/nums/values/4", "", 404, ""},
+            {"GET", "/trees/nums/values/x", "", 400, ""},
+            {"GET", "/trees/words/values/a", "", 200, `{"value":"a"}`},
+            {"GET", "/trees/nums/range?low=2&high=7", "", 200, `{"values":[3,5,7]}`},
+            {"GET", "/trees/nums/range?low=2", "", 400, ""},
+            {"GET", "/trees/nums/min", "", 200, `{"value":1}`},
+            {"GET", "/trees/nums/max", "", 200, `{"value":9}`},
+            {"GET", "/trees/nums/rank/6", "", 200, `{"rank":3}`},
+            {"GET", "/trees/nums/rank/x", "", 400, ""},
+            {"GET", "/trees/nums/select/1", "", 200, `{"value":3}`},
+            {"GET", "/trees/nums/select/5", "", 404, ""},
+            {"GET", "/trees/nums/select/x", "", 400, ""},
+            {"GET", "/trees/nums/stats", "", 200, ""},
+            {"GET", "/trees/nums/dot", "", 200, ""},
+            {"DELETE", "/trees/nums/values/3", "", 200, `{"deleted":true}`},
+            {"DELETE", "/trees/nums/values/3", "", 200, `{"deleted":false}`},
+            {"DELETE", "/trees/nums/values/x", "", 400, ""},
+            {"DELETE", "/trees/words", "", 204, ""},
+            {"DELETE", "/trees/words", "", 404, ""},
+            {"GET", "/trees/words/min", "", 404, ""},
+            {"POST", "/trees", "", 405, ""},
+      }
+      for _, test := range tests {
+            status, body := do(s, test.method, test.path, test.body)
+            if status != test.status {
+                  t.Fatalf("%s %s: código %d, se esperaba %d (%s)", test.method, test.path, status, test.status, body)
+            }
+            if test.want != "" && !sameJSON(body, test.want) {
+                  t.Fatalf("%s %s: respondió %s, se esperaba %s", test.method, test.path, body, test.want)
+            }
+            if status >= 400 && status != http.StatusMethodNotAllowed && !strings.Contains(body, `"error"`) {
+                  t.Fatalf("%s %s: el error no viene en JSON: %s", test.method, test.path, body)
+            }
+      }
+}
+
+func sameJSON(pGot, pWant string) bool {
+      var got, want interface{}
+      if json.Unmarshal([]byte(pGot), &got) != nil || json.Unmarshal([]byte(pWant), &want) != nil {
+            return false
+      }
+      return reflect.DeepEqual(got, want)
+}
+
+func TestStatsAndDot(t *testing.T) {
+      s := New()
+      s.Create("nums", "int")
+      do(s, "POST", "/trees/nums/values", `{"values": [1, 2, 3, 4, 5, 6, 7]}`)
+
+      _, body := do(s, "GET", "/trees/nums/stats", "")
+      var stats treeStats
+      if err := json.Unmarshal([]byte(body), &stats); err != nil {
+            t.Fatal(err)
+      }
+      if stats.Len != 7 || stats.Red+stats.Black != 7 || stats.Height < 3 {
+            t.Fatalf("stats = %+v", stats)
+      }
+
+      w := httptest.NewRecorder()
+      s.ServeHTTP(w, httptest.NewRequest("GET", "/trees/nums/dot", nil))
+      if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/vnd.graphviz") {
+            t.Fatalf("Content-Type = %q", ct)
+      }
+      if !strings.HasPrefix(w.Body.String(), "digraph") {
+            t.Fatalf("dot = %q", w.Body.String())
+      }
+}
+
+// Un cuerpo demasiado grande se rechaza sin leerlo completo.
+func TestInsertBodyTooLarge(t *testing.T) {
+      s := New()
+      s.Create("nums", "int")
+      body := `{"values": [` + strings.Repeat("1,", MaxBodySize) + `1]}`
+      status, response := do(s, "POST", "/trees/nums/values", body)
+      if status != http.StatusRequestEntityTooLarge {
+            t.Fatalf("código %d, se esperaba 413 (%s)", status, response)
+      }
+      if _, body := do(s, "GET", "/trees/nums/min", ""); !strings.Contains(body, "error") {
+            t.Fatalf("se insertaron valores de un cuerpo rechazado: %s", body)
+      }
+}
+
+// Varios lectores consultan mientras otros insertan y borran; sirve con
+// go test -race.
+func TestConcurrentReaders(t *testing.T) {
+      s := New()
+      s.Create("nums", "int")
+      srv := httptest.NewServer(s)
+      defer srv.Close()
+
+      var wg sync.WaitGroup
+      errs := make(chan error, 16)
+      for writer := 0; writer < 2; writer++ {
+            wg.Add(1)
+            go func(writer int) {
+                  defer wg.Done()
+                  for i := 0; i < 50; i++ {
+                        v := writer*1000 + i
+                        body := strings.NewReader(fmt.Sprintf(`{"values": [%d]}`, v))
+                        resp, err := http.Post(srv.URL+"/trees/nums/values", "application/json", body)
+                        if err != nil {
+                              errs <- err
+                              return
+                        }
+                        resp.Body.Close()
+                        if i%3 == 0 {
+                              req, _ := http.NewRequest("DELETE", fmt.Sprintf("%s/trees/nums/values/%d", srv.URL, v), nil)
+                              if resp, err = http.DefaultClient.Do(req); err != nil {
+                                    errs <- err
+                                    return
+                              }
+                              resp.Body.Close()
+                        }
+                  }
+            }(writer)
+      }
+      paths := []string{"/trees", "/trees/nums/range?low=0&high=2000", "/trees/nums/min",
+            "/trees/nums/rank/500", "/trees/nums/select/0", "/trees/nums/stats", "/trees/nums/dot"}
+      for reader := 0; reader < 4; reader++ {
+            wg.Add(1)
+            go func() {
+                  defer wg.Done()
+                  for i := 0; i < 50; i++ {
+                        resp, err := http.Get(srv.URL + paths[i%len(paths)])
+                        if err != nil {
+                              errs <- err
+                              return
+                        }
+                        resp.Body.Close()
+                        if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+                              errs <- fmt.Errorf("%s: código %d", paths[i%len(paths)], resp.StatusCode)
+                              return
+                        }
+                  }
+            }()
+      }
+      wg.Wait()
+      close(errs)
+      for err := range errs {
+            t.Fatal(err)
+      }
+
+      _, body := do(s, "GET", "/trees", "")
+      if !sameJSON(body, `{"trees":[{"name":"nums","type":"int","len":66}]}`) {
+            t.Fatalf("al terminar: %s", body)
+      }
+}
@@ -0,0 +1,82 @@
+/* Medidas de la forma del árbol, para monitorear qué tan balanceado está.
+   La profundidad de la raíz es 1 y la de un árbol vacío es 0.
+*/
+
+package redBlackTree
+
+// Stats resume la forma del árbol. MinDepth y MaxDepth son la profundidad
+// de la hoja (nodo sin hijos) menos y más profunda, y AverageDepth el
+// promedio de la profundidad de todos los nodos.
+type Stats struct {
+      Nodes        int
+      Red, Black   int
+      MinDepth     int
+      MaxDepth     int
+      AverageDepth float64
+}
+
+// Height devuelve la cantidad de nodos en el camino más largo de la raíz a
+// una hoja.
+func (tree *RBTree) Height() int {
+      var height func(*Node) int
+      height = func(node *Node) int {
+            if node == nil {
+                  return 0
+            }
+            left, right := height(node.left), height(node.right)
+            if left > right {
+                  return left + 1
+            }
+            return right + 1
+      }
+      return height(tree.root)
+}
+
+// BlackHeight devuelve la cantidad de nodos negros en cualquier camino de la
+// raíz a una hoja nula, contando la raíz. En un árbol válido es la misma para
+// todos los caminos, así que basta con seguir el de la izquierda.
+func (tree *RBTree) BlackHeight() int {
+      blacks := 0
+      for node := tree.root; node != nil; node = node.left {
+            if node.color == NEGRO {
+                  blacks++
+            }
+      }
+      return blacks
+}
+
+// Stats recorre el árbol y devuelve sus medidas.
+func (tree *RBTree) Stats() Stats {
+      stats := Stats{}
+      totalDepth := 0
+
+      var visit func(*Node, int)
+      visit = func(node *Node, depth int) {
+            if node == nil {
+                  return
+            }
+            stats.Nodes++
+            totalDepth += depth
+            if node.color == NEGRO {
+                  stats.Black++
+            } else {
+                  stats.Red++
+            }
+            if node.left == nil && node.right == nil {
+                  if stats.MinDepth == 0 || depth < stats.MinDepth {
+                        stats.MinDepth = depth
+                  }
+                  if depth > stats.MaxDepth {
+                        stats.MaxDepth = depth
+                  }
+            }
+            visit(node.left, depth+1)
+            visit(node.right, depth+1)
+      }
+      visit(tree.root, 1)
+
+      if stats.Nodes > 0 {
+            stats.AverageDepth = float64(totalDepth) / float64(stats.Nodes)
+      }
+      return stats
+}
@@ -0,0 +1,102 @@
+package redBlackTree
+
+import (
+      "math"
+      "math/rand"
+      "testing"
+)
+
+func TestStatsEmpty(t *testing.T) {
+      tree := NewTree(IntCmp)
+      if tree.Height() != 0 || tree.BlackHeight() != 0 || tree.Stats() != (Stats{}) {
+            t.Fatalf("árbol vacío: Height() = %d, BlackHeight() = %d, Stats() = %+v",
+                  tree.Height(), tree.BlackHeight(), tree.Stats())
+      }
+}
+
+// Insertar de 1 a 7 en orden deja siempre la misma forma:
+//
+//          2N
+//         /  \
+//       1N    4R
+//            /  \
+//          3N    6N
+//               /  \
+//             5R    7R
+func TestStatsKnownShape(t *testing.T) {
+      tree := NewTree(IntCmp)
+      for i := 1; i <= 7; i++ {
+            tree.Insert(i)
+      }
+      if tree.Height() != 4 {
+            t.Errorf("Height() = %d, se esperaba 4", tree.Height())
+      }
+      if tree.BlackHeight() != 2 {
+            t.Errorf("BlackHeight() = %d, se esperaba 2", tree.BlackHeight())
+      }
+      want := Stats{Nodes: 7, Red: 3, Black: 4, MinDepth: 2, MaxDepth: 4, AverageDepth: 19.0 / 7}
+      if got := tree.Stats(); got != want {
+            t.Errorf("Stats() = %+v, se esperaba %+v", got, want)
+      }
+}
+
+// En cualquier árbol válido la altura es a lo más 2·log2(n+1), y la altura
+// negra es la misma en todos los caminos.
+func TestStatsBounds(t *testing.T) {
+      r := rand.New(rand.NewSource(1))
+      tree := NewTree(IntCmp)
+      for step := 0; step < 2000; step++ {
+            if r.Intn(3) == 0 {
+                  tree.Delete(r.Intn(500))
+            } else {
+                  tree.Insert(r.Intn(500))
+            }
+            stats := tree.Stats()
+            if stats.Nodes != tree.Len() || stats.Red+stats.Black != stats.Nodes {
+                  t.Fatalf("paso %d: Stats() = %+v con Len() = %d", step, stats, tree.Len())
+            }
+            if limit := 2 * math.Log2(float64(tree.Len()+1)); float64(tree.Height()) > limit {
+                  t.Fatalf("paso %d: Height() = %d supera %.2f", step, tree.Height(), limit)
+            }
+            if stats.MaxDepth != tree.Height() || stats.MinDepth > stats.MaxDepth {
+                  t.Fatalf("paso %d: Stats() = %+v con Height() = %d", step, stats, tree.Height())
+            }
+            if blacks := blackHeightRight(tree.root); blacks != tree.BlackHeight() {
+                  t.Fatalf("paso %d: BlackHeight() = %d, por la derecha %d", step, tree.BlackHeight(), blacks)
+            }
+      }
+}
+
+// blackHeightRight cuenta los nodos negros siguiendo el camino de la derecha.
+func blackHeightRight(node *Node) int {
+      blacks := 0
+      for ; node != nil; node = node.right {
+            if node.color == NEGRO {
+                  blacks++
+            }
+      }
+      return blacks
+}
+
+// Borrar un valor ausente no cambia la cantidad de valores.
+func TestLenDeleteMissing(t *testing.T) {
+      tree := NewTree(IntCmp)
+      tree.Delete(1)
+      if tree.Len() != 0 || !tree.IsEmpty() {
+            t.Fatalf("árbol vacío: Len() = %d", tree.Len())
+      }
+      for _, v := range []int{5, 3, 8} {
+            tree.Insert(v)
+      }
+      for _, v := range []int{4, 0, 9, 5, 5} {
+            tree.Delete(v)
+      }
+      if tree.Len() != 2 || tree.IsEmpty() {
+            t.Fatalf("Len() = %d, se esperaba 2", tree.Len())
+      }
+      tree.Delete(3)
+      tree.Delete(8)
+      if tree.Len() != 0 || !tree.IsEmpty() {
+            t.Fatalf("Len() = %d después de borrar todo", tree.Len())
+      }
+}
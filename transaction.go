@@ -0,0 +1,153 @@
+/* Transacciones sobre el árbol.
+   Una transacción guarda sus Insert y Delete en un árbol aparte, con el
+   estado final de cada valor que tocó, sin modificar el árbol original. Las
+   consultas de la transacción revisan primero sus propios cambios y luego el
+   árbol original. Commit aplica todos los cambios juntos y Rollback los
+   descarta, de modo que el árbol original queda igual que antes.
+*/
+
+package redBlackTree
+
+import (
+      "errors"
+)
+
+// ErrTxnDone indica que la transacción ya terminó con Commit o Rollback.
+var ErrTxnDone = errors.New("la transacción ya terminó")
+
+// Cambio pendiente de una transacción: el valor y si quedó borrado.
+type txnWrite struct {
+      value   interface{}
+      deleted bool
+}
+
+// La transacción guarda el árbol original y los cambios pendientes, ordenados
+// con el comparador del árbol. No es segura para usarse desde varias goroutines.
+type Txn struct {
+      tree   *RBTree
+      writes *RBTree
+      count  int
+      done   bool
+}
+
+// Begin empieza una transacción sobre el árbol. El árbol no debe modificarse
+// por otro medio mientras la transacción esté abierta: Commit aplica el
+// estado final que vio la transacción, sin revisar conflictos.
+func (tree *RBTree) Begin() *Txn {
+      writes := NewTree(func(o1, o2 interface{}) int {
+            return tree.cmp(o1.(txnWrite).value, o2.(txnWrite).value)
+      })
+      return &Txn{tree: tree, writes: writes, count: tree.count}
+}
+
+// mustBeOpen entra en pánico si la transacción ya terminó.
+func (txn *Txn) mustBeOpen() {
+      if txn.done {
+            panic(ErrTxnDone)
+      }
+}
+
+// lookup busca pKey en los cambios pendientes y después en el árbol original.
+func (txn *Txn) lookup(pKey interface{}) (interface{}, bool) {
+      if node := txn.writes.lookup(txnWrite{value: pKey}); node != nil {
+            write := node.value.(txnWrite)
+            return write.value, !write.deleted
+      }
+      if node := txn.tree.lookup(pKey); node != nil {
+            return node.value, true
+      }
+      return nil, false
+}
+
+// record guarda el estado final de pValue, reemplazando el cambio anterior.
+func (txn *Txn) record(pValue interface{}, pDeleted bool) {
+      write := txnWrite{value: pValue, deleted: pDeleted}
+      if node := txn.writes.lookup(write); node != nil {
+            node.value = write
+            return
+      }
+      txn.writes.Insert(write)
+}
+
+// Insert agrega pValue a la transacción. Como RBTree.Insert, devuelve false
+// si el valor ya estaba, contando los cambios de la transacción.
+func (txn *Txn) Insert(pValue interface{}) bool {
+      txn.mustBeOpen()
+      txn.tree.mustCheckType(pValue)
+      if _, found := txn.lookup(pValue); found {
+            return false
+      }
+      txn.record(pValue, false)
+      txn.count++
+      return true
+}
+
+// Delete borra pKey en la transacción y devuelve si estaba, contando los
+// cambios de la transacción.
+func (txn *Txn) Delete(pKey interface{}) bool {
+      txn.mustBeOpen()
+      txn.tree.mustCheckType(pKey)
+      value, found := txn.lookup(pKey)
+      if !found {
+            return false
+      }
+      txn.record(value, true)
+      txn.count--
+      return true
+}
+
+// Find determina si pKey está en el árbol según la transacción y devuelve el
+// valor guardado.
+func (txn *Txn) Find(pKey interface{}) (bool, interface{}) {
+      txn.mustBeOpen()
+      txn.tree.mustCheckType(pKey)
+      value, found := txn.lookup(pKey)
+      return found, value
+}
+
+// FindKey determina si pKey está en el árbol según la transacción.
+func (txn *Txn) FindKey(pKey interface{}) bool {
+      found, _ := txn.Find(pKey)
+      return found
+}
+
+// Len devuelve la cantidad de valores que tendrá el árbol al hacer Commit.
+func (txn *Txn) Len() int {
+      return txn.count
+}
+
+// Commit aplica al árbol original los cambios de la transacción, en orden.
+// Los tipos de los valores se revisaron al agregarlos, así que aplicarlos no
+// falla a medias. Devuelve ErrTxnDone si la transacción ya había terminado.
+func (txn *Txn) Commit() error {
+      if txn.done {
+            return ErrTxnDone
+      }
+      txn.done = true
+
+      iter := &InorderIterator{}
+      for node := range iter.Iterate(txn.writes.root) {
+            write := node.value.(txnWrite)
+            switch {
+            case write.deleted:
+                  txn.tree.Delete(write.value)
+            // Si la transacción borró un valor y luego insertó otro igual
+            // según el comparador, el nuevo reemplaza al original.
+            case !txn.tree.Insert(write.value):
+                  txn.tree.Replace(write.value)
+            }
+      }
+      txn.writes.Clear()
+      return nil
+}
+
+// Rollback descarta los cambios de la transacción. Devuelve ErrTxnDone si la
+// transacción ya había terminado.
+func (txn *Txn) Rollback() error {
+      if txn.done {
+            return ErrTxnDone
+      }
+      txn.done = true
+      txn.writes.Clear()
+      return nil
+}
@@ -0,0 +1,179 @@
+package redBlackTree
+
+import (
+      "reflect"
+      "testing"
+)
+
+// Valor con una llave, que es lo único que compara entryCmp, y un dato.
+type entry struct {
+      key   int
+      label string
+}
+
+func entryCmp(o1, o2 interface{}) int {
+      return IntCmp(o1.(entry).key, o2.(entry).key)
+}
+
+func newIntTree(pValues ...int) *RBTree {
+      tree := NewTree(IntCmp)
+      for _, v := range pValues {
+            tree.Insert(v)
+      }
+      return tree
+}
+
+// Paso de una transacción sobre enteros: inserta v si insert es true y si
+// no lo borra; ok y count son lo que deben devolver la operación y Len.
+type txnStep struct {
+      insert bool
+      v      int
+      ok     bool
+      count  int
+}
+
+func runTxnSteps(t *testing.T, txn *Txn, pSteps []txnStep) {
+      t.Helper()
+      for i, step := range pSteps {
+            var ok bool
+            if step.insert {
+                  ok = txn.Insert(step.v)
+            } else {
+                  ok = txn.Delete(step.v)
+            }
+            if ok != step.ok || txn.Len() != step.count {
+                  t.Fatalf("paso %d %+v: devolvió %v con Len() = %d", i, step, ok, txn.Len())
+            }
+            if txn.FindKey(step.v) != step.insert {
+                  t.Fatalf("paso %d %+v: FindKey(%d) = %v", i, step, step.v, !step.insert)
+            }
+      }
+}
+
+var txnSteps = []txnStep{
+      {true, 9, true, 6},
+      {true, 9, false, 6},
+      {false, 3, true, 5},
+      {false, 3, false, 5},
+      {false, 8, false, 5},
+      {true, 0, true, 6},
+      {true, 3, true, 7},
+      {false, 3, true, 6},
+      {false, 9, true, 5},
+      {true, 4, true, 6},
+}
+
+// Rollback deja el árbol igual, con los mismos colores, y sin eventos.
+func TestTxnRollback(t *testing.T) {
+      tree := newIntTree(1, 2, 3, 5, 7)
+      before := tree.String()
+      events := 0
+      tree.Subscribe(func(Event) { events++ })
+
+      txn := tree.Begin()
+      runTxnSteps(t, txn, txnSteps)
+      if tree.String() != before {
+            t.Fatalf("la transacción modificó el árbol: %s", tree.String())
+      }
+      if err := txn.Rollback(); err != nil {
+            t.Fatal(err)
+      }
+      if tree.String() != before || tree.Len() != 5 || events != 0 {
+            t.Fatalf("después de Rollback: %s, Len() = %d, %d eventos", tree.String(), tree.Len(), events)
+      }
+}
+
+// Commit aplica el estado final de cada valor en el orden del comparador:
+// 9 se insertó y se borró, y 3 se borró, se insertó y se volvió a borrar.
+func TestTxnCommit(t *testing.T) {
+      tree := newIntTree(1, 2, 3, 5, 7)
+      events := []Event{}
+      tree.Subscribe(func(pEvent Event) { events = append(events, pEvent) })
+
+      txn := tree.Begin()
+      runTxnSteps(t, txn, txnSteps)
+      if err := txn.Commit(); err != nil {
+            t.Fatal(err)
+      }
+      want := []Event{{Kind: Inserted, Value: 0}, {Kind: Deleted, Value: 3}, {Kind: Inserted, Value: 4}}
+      if !reflect.DeepEqual(events, want) {
+            t.Fatalf("eventos %v, se esperaba %v", events, want)
+      }
+      if got := tree.contents(); !reflect.DeepEqual(got, []interface{}{0, 1, 2, 4, 5, 7}) {
+            t.Fatalf("el árbol tiene %v", got)
+      }
+      if tree.Len() != txn.Len() {
+            t.Fatalf("Len() = %d, la transacción tenía %d", tree.Len(), txn.Len())
+      }
+      if err := tree.Validate(); err != nil {
+            t.Fatal(err)
+      }
+}
+
+// Borrar un valor e insertar otro igual según el comparador lo reemplaza al
+// hacer Commit, pues Insert devuelve false.
+func TestTxnDeleteReinsertReplaces(t *testing.T) {
+      tree := NewTree(entryCmp)
+      tree.Insert(entry{1, "uno"})
+      tree.Insert(entry{2, "dos"})
+      events := []Event{}
+      tree.Subscribe(func(pEvent Event) { events = append(events, pEvent) })
+
+      txn := tree.Begin()
+      if !txn.Delete(entry{key: 1}) || txn.FindKey(entry{key: 1}) || txn.Len() != 1 {
+            t.Fatal("Delete no borró la llave 1 en la transacción")
+      }
+      if !txn.Insert(entry{1, "otro"}) || txn.Len() != 2 {
+            t.Fatal("Insert no volvió a insertar la llave 1")
+      }
+      if _, value := txn.Find(entry{key: 1}); value != (entry{1, "otro"}) {
+            t.Fatalf("Find = %v", value)
+      }
+      if err := txn.Commit(); err != nil {
+            t.Fatal(err)
+      }
+
+      want := []Event{{Kind: Replaced, Value: entry{1, "otro"}, Old: entry{1, "uno"}}}
+      if !reflect.DeepEqual(events, want) {
+            t.Fatalf("eventos %v, se esperaba %v", events, want)
+      }
+      if got := tree.contents(); !reflect.DeepEqual(got, []interface{}{entry{1, "otro"}, entry{2, "dos"}}) {
+            t.Fatalf("el árbol tiene %v", got)
+      }
+}
+
+// Una transacción terminada devuelve ErrTxnDone en Commit y Rollback, y
+// entra en pánico con ErrTxnDone en las demás operaciones.
+func TestTxnDone(t *testing.T) {
+      for _, finish := range []string{"Commit", "Rollback"} {
+            tree := newIntTree(1)
+            txn := tree.Begin()
+            txn.Insert(2)
+            if finish == "Commit" {
+                  txn.Commit()
+            } else {
+                  txn.Rollback()
+            }
+            if txn.Commit() != ErrTxnDone || txn.Rollback() != ErrTxnDone {
+                  t.Fatalf("%s: Commit o Rollback no devolvió ErrTxnDone", finish)
+            }
+            ops := map[string]func(){
+                  "Insert": func() { txn.Insert(3) },
+                  "Delete": func() { txn.Delete(1) },
+                  "Find":   func() { txn.Find(1) },
+            }
+            for name, op := range ops {
+                  func() {
+                        defer func() {
+                              if r := recover(); r != ErrTxnDone {
+                                    t.Fatalf("%s y luego %s: pánico %v, se esperaba ErrTxnDone", finish, name, r)
+                              }
+                        }()
+                        op()
+                  }()
+            }
+            if tree.FindKey(3) {
+                  t.Fatalf("%s: se insertó un valor después de terminar", finish)
+            }
+      }
+}
@@ -0,0 +1,75 @@
+/* Operaciones comunes de RBTree y CompactTree.
+   CompactTree no tiene objetos Node, así que no puede devolver nodos como
+   Find, Min, Max, Range o Root de RBTree. Las operaciones que ambos ofrecen
+   trabajan con los valores y forman la interfaz Tree, de modo que puede
+   usarse cualquiera de los dos árboles donde no hacen falta los nodos.
+*/
+
+package redBlackTree
+
+import (
+      "io"
+)
+
+// Tree es la interfaz común de los árboles rojinegros del paquete.
+type Tree interface {
+      Insert(pValue interface{}) bool
+      Delete(pKey interface{})
+      Clear()
+      FindKey(pKey interface{}) bool
+      Get(pKey interface{}) (interface{}, bool)
+      MinValue() (interface{}, bool)
+      MaxValue() (interface{}, bool)
+      RangeValues(pLow, pHigh interface{}) []interface{}
+      Each(fn func(interface{}) bool)
+      Len() int
+      String() string
+      PrettyPrint()
+      PrettyFprint(w io.Writer)
+      Dot() string
+      Validate() error
+}
+
+var (
+      _ Tree = (*RBTree)(nil)
+      _ Tree = (*CompactTree)(nil)
+)
+
+// Get devuelve el valor guardado igual a pKey, o false si no está.
+func (tree *RBTree) Get(pKey interface{}) (interface{}, bool) {
+      found, node := tree.Find(pKey)
+      return valueOf(node), found
+}
+
+// MinValue devuelve el valor más pequeño, o false si el árbol está vacío.
+func (tree *RBTree) MinValue() (interface{}, bool) {
+      node := tree.Min()
+      return valueOf(node), node != nil
+}
+
+// MaxValue devuelve el valor más grande, o false si el árbol está vacío.
+func (tree *RBTree) MaxValue() (interface{}, bool) {
+      node := tree.Max()
+      return valueOf(node), node != nil
+}
+
+// RangeValues devuelve, en orden, los valores en el intervalo cerrado
+// [pLow, pHigh].
+func (tree *RBTree) RangeValues(pLow, pHigh interface{}) []interface{} {
+      nodes := tree.Range(pLow, pHigh)
+      values := make([]interface{}, len(nodes))
+      for i, node := range nodes {
+            values[i] = node.value
+      }
+      return values
+}
+
+// Each aplica fn a los valores en in-order hasta que fn devuelva false.
+// A diferencia de los iteradores, no usa una goroutine.
+func (tree *RBTree) Each(fn func(interface{}) bool) {
+      for node := tree.Min(); node != nil; node = node.next() {
+            if !fn(node.value) {
+                  return
+            }
+      }
+}
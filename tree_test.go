@@ -0,0 +1,78 @@
+package redBlackTree
+
+import (
+      "math/rand"
+      "reflect"
+      "strings"
+      "testing"
+)
+
+// Aplica las mismas operaciones a un RBTree y a un CompactTree mediante la
+// interfaz Tree y revisa que ambos respondan igual.
+func TestTreeImplementationsAgree(t *testing.T) {
+      r := rand.New(rand.NewSource(1))
+      trees := []Tree{NewTree(IntCmp), NewCompactTree(IntCmp)}
+
+      for step := 0; step < 3000; step++ {
+            v := r.Intn(200)
+            switch op := r.Intn(20); {
+            case op == 0:
+                  for _, tree := range trees {
+                        tree.Clear()
+                  }
+            case op < 11:
+                  inserted := trees[0].Insert(v)
+                  if trees[1].Insert(v) != inserted {
+                        t.Fatalf("paso %d: Insert(%d) no coincide", step, v)
+                  }
+            default:
+                  for _, tree := range trees {
+                        tree.Delete(v)
+                  }
+            }
+
+            results := make([][]interface{}, len(trees))
+            for i, tree := range trees {
+                  if err := tree.Validate(); err != nil {
+                        t.Fatalf("paso %d, árbol %d: %v", step, i, err)
+                  }
+                  got, found := tree.Get(v)
+                  min, hasMin := tree.MinValue()
+                  max, hasMax := tree.MaxValue()
+                  results[i] = []interface{}{
+                        tree.Len(), tree.FindKey(v), got, found, min, hasMin, max, hasMax,
+                        tree.RangeValues(50, 120), tree.String(),
+                  }
+            }
+            if !reflect.DeepEqual(results[0], results[1]) {
+                  t.Fatalf("paso %d: %v != %v", step, results[0], results[1])
+            }
+      }
+
+      var pretty [2]strings.Builder
+      for i, tree := range trees {
+            tree.PrettyFprint(&pretty[i])
+      }
+      if pretty[0].String() != pretty[1].String() {
+            t.Fatalf("PrettyFprint no coincide:\n%s\n%s", pretty[0].String(), pretty[1].String())
+      }
+}
+
+func TestTreeEachStops(t *testing.T) {
+      for _, tree := range []Tree{NewTree(IntCmp), NewCompactTree(IntCmp)} {
+            for i := 0; i < 10; i++ {
+                  tree.Insert(i)
+            }
+            seen := []interface{}{}
+            tree.Each(func(v interface{}) bool {
+                  seen = append(seen, v)
+                  return len(seen) < 4
+            })
+            if !reflect.DeepEqual(seen, []interface{}{0, 1, 2, 3}) {
+                  t.Errorf("%T: Each visitó %v", tree, seen)
+            }
+            if strings.Count(tree.Dot(), "->") != 2*tree.Len() {
+                  t.Errorf("%T: Dot no tiene una arista por cada hijo", tree)
+            }
+      }
+}